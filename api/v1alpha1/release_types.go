@@ -25,6 +25,64 @@ func (r ReleaseState) String() string {
 	return string(r)
 }
 
+type ClusterUsageReason string
+
+var (
+	// ClusterUsageReasonReleaseLabel means the release version label of the
+	// cluster matches the release.
+	ClusterUsageReasonReleaseLabel ClusterUsageReason = "ReleaseLabel"
+	// ClusterUsageReasonOperatorVersion means the provider operator version
+	// label of the cluster matches the operator version in the release.
+	ClusterUsageReasonOperatorVersion ClusterUsageReason = "OperatorVersion"
+	// ClusterUsageReasonKVMPods means kvm-operator pods of the release's
+	// kvm-operator version still exist in the namespace of the cluster.
+	ClusterUsageReasonKVMPods ClusterUsageReason = "KVMPods"
+)
+
+const (
+	// ConditionComponentsDeployed is true when the Apps of all components
+	// deployed by release-operator report the deployed status.
+	ConditionComponentsDeployed = "ComponentsDeployed"
+	// ConditionConfigsReady is true when the Configs of all components deployed
+	// by release-operator have been generated by config-controller.
+	ConditionConfigsReady = "ConfigsReady"
+	// ConditionInUse is true when at least one cluster uses the release.
+	ConditionInUse = "InUse"
+	// ConditionEndOfLife is true when the end of life date of the release has
+	// passed.
+	ConditionEndOfLife = "EndOfLife"
+	// ConditionAppDependenciesResolved is false when the components or apps
+	// deployed by release-operator depend on each other in a cycle and can
+	// therefore never be created.
+	ConditionAppDependenciesResolved = "AppDependenciesResolved"
+	// ConditionClusterDiscoveryDegraded is true when the clusters using the
+	// release could not be fully discovered. The previous InUse value is kept
+	// then.
+	ConditionClusterDiscoveryDegraded = "ClusterDiscoveryDegraded"
+	// ConditionStateTransitionAccepted is false when release-operator rejected
+	// the last change of spec.state. status.state keeps the previously
+	// accepted state then.
+	ConditionStateTransitionAccepted = "StateTransitionAccepted"
+)
+
+const (
+	ReasonComponentsDeployed    = "ComponentsDeployed"
+	ReasonComponentsNotDeployed = "ComponentsNotDeployed"
+	ReasonConfigsReady          = "ConfigsReady"
+	ReasonConfigsNotReady       = "ConfigsNotReady"
+	ReasonUsedByClusters        = "UsedByClusters"
+	ReasonNotUsed               = "NotUsed"
+	ReasonEndOfLifeReached      = "EndOfLifeReached"
+	ReasonEndOfLifeNotReached   = "EndOfLifeNotReached"
+	ReasonNoEndOfLifeDate       = "NoEndOfLifeDate"
+	ReasonDependenciesResolved  = "DependenciesResolved"
+	ReasonDependencyCycle       = "DependencyCycle"
+	ReasonDiscoveryFailed       = "DiscoveryFailed"
+	ReasonDiscoverySucceeded    = "DiscoverySucceeded"
+	ReasonStateAccepted         = "StateAccepted"
+	ReasonStateRejected         = "StateRejected"
+)
+
 func NewReleaseTypeMeta() metav1.TypeMeta {
 	return metav1.TypeMeta{
 		APIVersion: SchemeGroupVersion.String(),
@@ -49,6 +107,7 @@ func NewReleaseCR() *Release {
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.spec.date`,description="Time since release creation"
 // +kubebuilder:printcolumn:name="Ready",type=boolean,JSONPath=`.status.ready`,description="Whether or not the release is ready"
 // +kubebuilder:printcolumn:name="InUse",type=boolean,JSONPath=`.status.inUse`,description="Whether or not the release is in use"
+// +kubebuilder:printcolumn:name="Clusters",type=integer,JSONPath=`.status.clusterCount`,priority=1,description="Number of clusters using the release"
 // +kubebuilder:printcolumn:name="Release notes",type=string,JSONPath=`.metadata.annotations['giantswarm\.io/release-notes']`,priority=1,description="Release notes for this release"
 // +kubebuilder:resource:scope=Cluster,categories=common;giantswarm
 // +genclient
@@ -98,22 +157,83 @@ type ReleaseSpec struct {
 
 // +k8s:openapi-gen=true
 type ReleaseSpecComponent struct {
+	// +kubebuilder:validation:Optional
+	// AppOperatorVersion is the app-operator version label set on the App CR of the component.
+	// Defaults to the operator wide default.
+	AppOperatorVersion string `json:"appOperatorVersion,omitempty"`
 	// +kubebuilder:default=control-plane-catalog
 	// Catalog specifies the name of the app catalog that this component belongs to.
 	Catalog string `json:"catalog,omitempty"`
+	// +kubebuilder:validation:Optional
+	// DependsOn is the list of components whose apps have to be deployed before this component is deployed.
+	// Only components deployed by release-operator are considered.
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// +kubebuilder:validation:Optional
+	// ExtraAnnotations are added to the App CR of the component.
+	ExtraAnnotations map[string]string `json:"extraAnnotations,omitempty"`
+	// +kubebuilder:validation:Optional
+	// ExtraLabels are added to the App CR of the component. They cannot override labels set by release-operator.
+	ExtraLabels map[string]string `json:"extraLabels,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m))+$"
+	// InstallTimeout is the timeout of the Helm install of the component. Defaults to the operator wide default.
+	InstallTimeout *metav1.Duration `json:"installTimeout,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// KubeConfigSecret references the Secret holding the kubeconfig used to install the component.
+	// Defaults to in-cluster credentials.
+	KubeConfigSecret *ReleaseSpecObjectReference `json:"kubeConfigSecret,omitempty"`
 	// Name of the component.
 	Name string `json:"name"`
 	// +kubebuilder:validation:Optional
+	// Namespace is the namespace the component is installed into, set as spec.namespace of its App CR which itself is always created in the giantswarm namespace. Defaults to the operator wide default.
+	Namespace string `json:"namespace,omitempty"`
+	// +kubebuilder:validation:Optional
 	// Reference is the component's version in the catalog (e.g. 1.2.3 or 1.2.3-abc8675309).
 	Reference string `json:"reference,omitempty"`
 	// +kubebuilder:validation:Optional
 	// ReleaseOperatorDeploy informs the release-operator that it should deploy the component.
 	ReleaseOperatorDeploy bool `json:"releaseOperatorDeploy,omitempty"`
+	// +kubebuilder:validation:Optional
+	// SkipConfig disables the generation of the component's configuration by config-controller. No Config CR
+	// is created and the App CR is created right away without config refs.
+	SkipConfig bool `json:"skipConfig,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m))+$"
+	// UpgradeTimeout is the timeout of the Helm upgrade of the component. Defaults to the operator wide default.
+	UpgradeTimeout *metav1.Duration `json:"upgradeTimeout,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// UserConfig references a ConfigMap and Secret holding installation specific values of the component.
+	// The App CR of the component is only created once all referenced objects exist.
+	UserConfig *ReleaseSpecComponentUserConfig `json:"userConfig,omitempty"`
 	// +kubebuilder:validation:Pattern=`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`
 	// Version of the component.
 	Version string `json:"version"`
 }
 
+// +k8s:openapi-gen=true
+type ReleaseSpecComponentUserConfig struct {
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// ConfigMap references a ConfigMap holding user values of the component.
+	ConfigMap *ReleaseSpecObjectReference `json:"configMap,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// Secret references a Secret holding user secret values of the component.
+	Secret *ReleaseSpecObjectReference `json:"secret,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+type ReleaseSpecObjectReference struct {
+	// Name of the referenced object.
+	Name string `json:"name"`
+	// Namespace of the referenced object.
+	Namespace string `json:"namespace"`
+}
+
 // +k8s:openapi-gen=true
 type ReleaseSpecApp struct {
 	// +kubebuilder:validation:Optional
@@ -126,6 +246,9 @@ type ReleaseSpecApp struct {
 	DependsOn []string `json:"dependsOn,omitempty"`
 	// Name of the app.
 	Name string `json:"name"`
+	// +kubebuilder:validation:Optional
+	// ReleaseOperatorDeploy informs the release-operator that it should deploy the app on the management cluster.
+	ReleaseOperatorDeploy bool `json:"releaseOperatorDeploy,omitempty"`
 	// +kubebuilder:validation:Pattern=`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`
 	// Version of the app.
 	Version string `json:"version"`
@@ -139,6 +262,88 @@ type ReleaseStatus struct {
 	// +kubebuilder:validation:Optional
 	// InUse indicates whether a release is actually used by a cluster.
 	InUse bool `json:"inUse"`
+	// +kubebuilder:validation:Optional
+	// ObservedGeneration is the most recent generation of the release observed by release-operator.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	// Conditions explain the current state of the release, e.g. which component is blocking readiness.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// +kubebuilder:validation:Optional
+	// Components describes the deployment state of each component deployed by release-operator.
+	Components []ReleaseStatusComponent `json:"components,omitempty"`
+	// +kubebuilder:validation:Optional
+	// InUseBy lists the clusters using the release and why they were matched.
+	InUseBy []ReleaseStatusCluster `json:"inUseBy,omitempty"`
+	// +kubebuilder:validation:Optional
+	// ClusterCount is the number of clusters using the release.
+	ClusterCount int `json:"clusterCount"`
+	// +kubebuilder:validation:Optional
+	// State is the effective state of the release. It is deprecated once the end of life date passed, even if
+	// spec.state is not.
+	State ReleaseState `json:"state,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// LastUsedTime is the time the release was last observed in use. It is set when the release stops being used,
+	// or when it is first observed unused, and cleared while clusters use it.
+	LastUsedTime *metav1.Time `json:"lastUsedTime,omitempty"`
+	// +kubebuilder:validation:Optional
+	// StateHistory records the most recent transitions of spec.state, oldest first.
+	StateHistory []ReleaseStatusStateTransition `json:"stateHistory,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+type ReleaseStatusStateTransition struct {
+	// +kubebuilder:validation:Optional
+	// From is the previous state of the release, empty for the state the release was first observed in.
+	From ReleaseState `json:"from,omitempty"`
+	// To is the state of the release after the transition.
+	To ReleaseState `json:"to"`
+	// Time is when release-operator observed the transition.
+	Time metav1.Time `json:"time"`
+	// +kubebuilder:validation:Optional
+	// User is the name of the user who changed the state as reported by the admission webhook. It is empty when
+	// the webhook is disabled.
+	User string `json:"user,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+type ReleaseStatusCluster struct {
+	// +kubebuilder:validation:Optional
+	// Namespace of the cluster, empty for clusters only detected by their namespace.
+	Namespace string `json:"namespace,omitempty"`
+	// Name of the cluster.
+	Name string `json:"name"`
+	// +kubebuilder:validation:Enum=ReleaseLabel;OperatorVersion;KVMPods
+	// Reason describes why the cluster is considered to use the release.
+	Reason ClusterUsageReason `json:"reason"`
+}
+
+// +k8s:openapi-gen=true
+type ReleaseStatusComponent struct {
+	// Name of the component.
+	Name string `json:"name"`
+	// Version of the component.
+	Version string `json:"version"`
+	// +kubebuilder:validation:Optional
+	// AppName is the name of the App CR deploying the component, empty if it does not exist yet.
+	AppName string `json:"appName,omitempty"`
+	// +kubebuilder:validation:Optional
+	// ConfigName is the name of the Config CR configuring the component, empty if it does not exist yet.
+	ConfigName string `json:"configName,omitempty"`
+	// +kubebuilder:validation:Optional
+	// AppStatus is the release status reported by the App CR, e.g. deployed.
+	AppStatus string `json:"appStatus,omitempty"`
+	// +kubebuilder:validation:Optional
+	// DeployedVersion is the version reported by the App CR as deployed.
+	DeployedVersion string `json:"deployedVersion,omitempty"`
+	// +kubebuilder:validation:Optional
+	// LastTransitionTime is the last time the app status of the component changed.
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+	// +kubebuilder:validation:Optional
+	// WaitingFor lists the apps of components which have to be deployed before the app of this component is created.
+	WaitingFor []string `json:"waitingFor,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
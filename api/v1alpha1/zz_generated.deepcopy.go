@@ -1,8 +1,7 @@
 //go:build !ignore_autogenerated
-// +build !ignore_autogenerated
 
 /*
-Copyright 2023 Giant Swarm GmbH.
+Copyright 2026 Giant Swarm GmbH.
 
 Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
@@ -22,6 +21,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -47,7 +47,7 @@ func (in *Release) DeepCopyInto(out *Release) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Release.
@@ -113,7 +113,9 @@ func (in *ReleaseSpec) DeepCopyInto(out *ReleaseSpec) {
 	if in.Components != nil {
 		in, out := &in.Components, &out.Components
 		*out = make([]ReleaseSpecComponent, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Date != nil {
 		in, out := &in.Date, &out.Date
@@ -158,6 +160,45 @@ func (in *ReleaseSpecApp) DeepCopy() *ReleaseSpecApp {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReleaseSpecComponent) DeepCopyInto(out *ReleaseSpecComponent) {
 	*out = *in
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExtraAnnotations != nil {
+		in, out := &in.ExtraAnnotations, &out.ExtraAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExtraLabels != nil {
+		in, out := &in.ExtraLabels, &out.ExtraLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.InstallTimeout != nil {
+		in, out := &in.InstallTimeout, &out.InstallTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.KubeConfigSecret != nil {
+		in, out := &in.KubeConfigSecret, &out.KubeConfigSecret
+		*out = new(ReleaseSpecObjectReference)
+		**out = **in
+	}
+	if in.UpgradeTimeout != nil {
+		in, out := &in.UpgradeTimeout, &out.UpgradeTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.UserConfig != nil {
+		in, out := &in.UserConfig, &out.UserConfig
+		*out = new(ReleaseSpecComponentUserConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseSpecComponent.
@@ -170,9 +211,79 @@ func (in *ReleaseSpecComponent) DeepCopy() *ReleaseSpecComponent {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseSpecComponentUserConfig) DeepCopyInto(out *ReleaseSpecComponentUserConfig) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(ReleaseSpecObjectReference)
+		**out = **in
+	}
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(ReleaseSpecObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseSpecComponentUserConfig.
+func (in *ReleaseSpecComponentUserConfig) DeepCopy() *ReleaseSpecComponentUserConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseSpecComponentUserConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseSpecObjectReference) DeepCopyInto(out *ReleaseSpecObjectReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseSpecObjectReference.
+func (in *ReleaseSpecObjectReference) DeepCopy() *ReleaseSpecObjectReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseSpecObjectReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReleaseStatus) DeepCopyInto(out *ReleaseStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]ReleaseStatusComponent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InUseBy != nil {
+		in, out := &in.InUseBy, &out.InUseBy
+		*out = make([]ReleaseStatusCluster, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastUsedTime != nil {
+		in, out := &in.LastUsedTime, &out.LastUsedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.StateHistory != nil {
+		in, out := &in.StateHistory, &out.StateHistory
+		*out = make([]ReleaseStatusStateTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseStatus.
@@ -184,3 +295,58 @@ func (in *ReleaseStatus) DeepCopy() *ReleaseStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseStatusCluster) DeepCopyInto(out *ReleaseStatusCluster) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseStatusCluster.
+func (in *ReleaseStatusCluster) DeepCopy() *ReleaseStatusCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseStatusCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseStatusComponent) DeepCopyInto(out *ReleaseStatusComponent) {
+	*out = *in
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.WaitingFor != nil {
+		in, out := &in.WaitingFor, &out.WaitingFor
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseStatusComponent.
+func (in *ReleaseStatusComponent) DeepCopy() *ReleaseStatusComponent {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseStatusComponent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseStatusStateTransition) DeepCopyInto(out *ReleaseStatusStateTransition) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseStatusStateTransition.
+func (in *ReleaseStatusStateTransition) DeepCopy() *ReleaseStatusStateTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseStatusStateTransition)
+	in.DeepCopyInto(out)
+	return out
+}
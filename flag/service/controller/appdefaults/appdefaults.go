@@ -0,0 +1,10 @@
+package appdefaults
+
+// AppDefaults is a data structure to hold the operator wide defaults for the
+// App CRs of components.
+type AppDefaults struct {
+	AppOperatorVersion string
+	InstallTimeout     string
+	Namespace          string
+	UpgradeTimeout     string
+}
@@ -0,0 +1,19 @@
+package controller
+
+import (
+	"github.com/giantswarm/release-operator/v4/flag/service/controller/appdefaults"
+)
+
+// Controller is a data structure to hold controller specific command line
+// configuration flags.
+type Controller struct {
+	AppDefaults                appdefaults.AppDefaults
+	CheckUserConfigSecrets     string
+	ConfigWaitThreshold        string
+	DeprecateEndOfLifeReleases string
+	DryRun                     string
+	MaxAppDeletions            string
+	MaxReleaseDeletions        string
+	ReleaseRetention           string
+	SkipConfigs                string
+}
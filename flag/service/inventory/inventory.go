@@ -0,0 +1,7 @@
+package inventory
+
+// Inventory is a data structure to hold cluster inventory specific command
+// line configuration flags.
+type Inventory struct {
+	Sources string
+}
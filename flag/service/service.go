@@ -1,8 +1,17 @@
 package service
 
-import "github.com/giantswarm/operatorkit/v7/pkg/flag/service/kubernetes"
+import (
+	"github.com/giantswarm/operatorkit/v7/pkg/flag/service/kubernetes"
+
+	"github.com/giantswarm/release-operator/v4/flag/service/controller"
+	"github.com/giantswarm/release-operator/v4/flag/service/inventory"
+	"github.com/giantswarm/release-operator/v4/flag/service/webhook"
+)
 
 // Service is an intermediate data structure for command line configuration flags.
 type Service struct {
+	Controller controller.Controller
+	Inventory  inventory.Inventory
 	Kubernetes kubernetes.Kubernetes
+	Webhook    webhook.Webhook
 }
@@ -0,0 +1,8 @@
+package tls
+
+// TLS is a data structure to hold the command line configuration flags of the
+// admission webhook serving certificate.
+type TLS struct {
+	CrtFile string
+	KeyFile string
+}
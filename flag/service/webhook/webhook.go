@@ -0,0 +1,14 @@
+package webhook
+
+import (
+	"github.com/giantswarm/release-operator/v4/flag/service/webhook/tls"
+)
+
+// Webhook is a data structure to hold admission webhook specific command line
+// configuration flags.
+type Webhook struct {
+	Address               string
+	EnforceStatePromotion string
+	PromotionSoakTime     string
+	TLS                   tls.TLS
+}
@@ -22,3 +22,7 @@ room for such suffix.
 {{- define "resource.default.namespace" -}}
 {{ .Release.Namespace }}
 {{- end -}}
+
+{{- define "resource.webhook.name" -}}
+{{- include "resource.default.name" . -}}-webhook
+{{- end -}}
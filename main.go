@@ -1,6 +1,8 @@
 package main
 
 import (
+	"time"
+
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/microkit/command"
 	microserver "github.com/giantswarm/microkit/server"
@@ -60,6 +62,7 @@ func mainWithError() (err error) {
 		var newServer microserver.Server
 		{
 			c := server.Config{
+				Flag:    f,
 				Logger:  newLogger,
 				Service: newService,
 				Viper:   v,
@@ -69,6 +72,10 @@ func mainWithError() (err error) {
 			if err != nil {
 				panic(microerror.JSON(err))
 			}
+
+			// microkit only serves the endpoints of the custom server.
+			// Booting it starts the admission webhook server.
+			go newServer.Boot()
 		}
 
 		return newServer
@@ -96,12 +103,30 @@ func mainWithError() (err error) {
 
 	daemonCommand := newCommand.DaemonCommand().CobraCommand()
 
+	daemonCommand.PersistentFlags().String(f.Service.Controller.AppDefaults.AppOperatorVersion, "0.0.0", "Default app-operator version label of component App CRs.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Controller.AppDefaults.InstallTimeout, 0, "Default Helm install timeout of component App CRs. When zero app-operator's default is used.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.AppDefaults.Namespace, "giantswarm", "Default namespace components are installed into. App CRs themselves are always created in the giantswarm namespace.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Controller.AppDefaults.UpgradeTimeout, 0, "Default Helm upgrade timeout of component App CRs. When zero app-operator's default is used.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Controller.CheckUserConfigSecrets, true, "Whether to only apply App CRs once the user values Secrets they reference exist. Requires get permissions on Secrets in all namespaces.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Controller.ConfigWaitThreshold, 10*time.Minute, "Duration a component may wait for its Config before a warning event is emitted on its releases.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Controller.DeprecateEndOfLifeReleases, false, "Whether to set the state of releases to deprecated in their spec once their end of life date passed.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Controller.DryRun, false, "Whether to only report planned App and Config changes as logs, events and metrics without applying them.")
+	daemonCommand.PersistentFlags().Int(f.Service.Controller.MaxAppDeletions, 5, "Maximum number of obsolete Apps deleted in a single loop. When more Apps are obsolete, none of them is deleted. Zero disables App deletions.")
+	daemonCommand.PersistentFlags().Int(f.Service.Controller.MaxReleaseDeletions, 5, "Maximum number of unused deprecated Releases deleted in a single loop. When more Releases expired, none of them is deleted. Zero disables Release deletions.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Controller.ReleaseRetention, 0, "Duration a deprecated Release must have been unused before it is deleted. When zero Releases are never deleted.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Controller.SkipConfigs, false, "Whether to deploy all components without Config CRs, e.g. on installations without config-controller.")
+	daemonCommand.PersistentFlags().String(f.Service.Inventory.Sources, "", "JSON list of the resources representing clusters, each with group, plural resource name, releaseLabel and optionally version, operatorLabel and operatorName. When empty CAPI Clusters, AWSClusters and KVMConfigs are discovered.")
 	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.Address, "", "Address used to connect to Kubernetes. When empty in-cluster config is created.")
 	daemonCommand.PersistentFlags().Bool(f.Service.Kubernetes.InCluster, true, "Whether to use the in-cluster config to authenticate with Kubernetes.")
 	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.KubeConfig, "", "KubeConfig used to connect to Kubernetes. When empty other settings are used.")
 	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.TLS.CAFile, "", "Certificate authority file path to use to authenticate with Kubernetes.")
 	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.TLS.CrtFile, "", "Certificate file path to use to authenticate with Kubernetes.")
 	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.TLS.KeyFile, "", "Key file path to use to authenticate with Kubernetes.")
+	daemonCommand.PersistentFlags().String(f.Service.Webhook.Address, "", "Address the admission webhooks are served on with TLS, e.g. 0.0.0.0:8443. When empty the webhooks are not served.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Webhook.EnforceStatePromotion, false, "Whether to require Releases to be created as wip or preview and to be ready for the promotion soak time before they become active.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Webhook.PromotionSoakTime, 0, "Duration all components of a Release must be deployed before it may become active when state promotion is enforced.")
+	daemonCommand.PersistentFlags().String(f.Service.Webhook.TLS.CrtFile, "", "Certificate file path the admission webhooks are served with.")
+	daemonCommand.PersistentFlags().String(f.Service.Webhook.TLS.KeyFile, "", "Key file path of the certificate the admission webhooks are served with.")
 
 	err = newCommand.CobraCommand().Execute()
 	if err != nil {
@@ -0,0 +1,32 @@
+package mutate
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var invalidRequestError = &microerror.Error{
+	Kind: "invalidRequestError",
+}
+
+// IsInvalidRequest asserts invalidRequestError.
+func IsInvalidRequest(err error) bool {
+	return microerror.Cause(err) == invalidRequestError
+}
+
+var wrongTypeError = &microerror.Error{
+	Kind: "wrongTypeError",
+}
+
+// IsWrongTypeError asserts wrongTypeError.
+func IsWrongTypeError(err error) bool {
+	return microerror.Cause(err) == wrongTypeError
+}
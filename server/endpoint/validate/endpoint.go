@@ -0,0 +1,120 @@
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+	admissionv1 "k8s.io/api/admission/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// Method is the HTTP method this endpoint is registered for.
+	Method = "POST"
+	// Name identifies the endpoint. It is aligned to the package path.
+	Name = "validate"
+	// Path is the HTTP request path this endpoint is registered for. It is
+	// referenced by the ValidatingWebhookConfiguration in the Helm chart.
+	Path = "/validate/release"
+)
+
+type Config struct {
+	Logger  micrologger.Logger
+	Handler admission.Handler
+}
+
+// Endpoint serves AdmissionReview requests for Release CRs sent by the
+// Kubernetes API server.
+type Endpoint struct {
+	logger  micrologger.Logger
+	handler admission.Handler
+}
+
+func New(config Config) (*Endpoint, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Handler == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Handler must not be empty", config)
+	}
+
+	e := &Endpoint{
+		logger:  config.Logger,
+		handler: config.Handler,
+	}
+
+	return e, nil
+}
+
+func (e *Endpoint) Decoder() kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		var review admissionv1.AdmissionReview
+		err := json.NewDecoder(r.Body).Decode(&review)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		if review.Request == nil {
+			return nil, microerror.Maskf(invalidRequestError, "admission review must contain a request")
+		}
+
+		return review, nil
+	}
+}
+
+func (e *Endpoint) Encoder() kithttp.EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+		review, ok := response.(admissionv1.AdmissionReview)
+		if !ok {
+			return microerror.Maskf(wrongTypeError, "expected '%T' got '%T'", admissionv1.AdmissionReview{}, response)
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		return json.NewEncoder(w).Encode(review)
+	}
+}
+
+func (e *Endpoint) Endpoint() kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		review, ok := request.(admissionv1.AdmissionReview)
+		if !ok {
+			return nil, microerror.Maskf(wrongTypeError, "expected '%T' got '%T'", admissionv1.AdmissionReview{}, request)
+		}
+
+		req := admission.Request{
+			AdmissionRequest: *review.Request,
+		}
+
+		res := e.handler.Handle(ctx, req)
+		err := res.Complete(req)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		review.Request = nil
+		review.Response = &res.AdmissionResponse
+
+		return review, nil
+	}
+}
+
+func (e *Endpoint) Method() string {
+	return Method
+}
+
+func (e *Endpoint) Middlewares() []kitendpoint.Middleware {
+	return []kitendpoint.Middleware{}
+}
+
+func (e *Endpoint) Name() string {
+	return Name
+}
+
+func (e *Endpoint) Path() string {
+	return Path
+}
@@ -10,13 +10,16 @@ import (
 	"github.com/giantswarm/micrologger"
 	"github.com/spf13/viper"
 
+	"github.com/giantswarm/release-operator/v4/flag"
 	"github.com/giantswarm/release-operator/v4/pkg/project"
 	"github.com/giantswarm/release-operator/v4/server/endpoint"
+	"github.com/giantswarm/release-operator/v4/server/webhook"
 	"github.com/giantswarm/release-operator/v4/service"
 )
 
 // Config represents the configuration used to construct server object.
 type Config struct {
+	Flag    *flag.Flag
 	Logger  micrologger.Logger
 	Service *service.Service
 	Viper   *viper.Viper
@@ -25,6 +28,9 @@ type Config struct {
 type Server struct {
 	// Dependencies.
 	logger micrologger.Logger
+	// webhookServer serves the admission webhooks with TLS on its own
+	// address. It is nil when no webhook address is configured.
+	webhookServer *webhook.Server
 
 	// Internals.
 	bootOnce     sync.Once
@@ -49,9 +55,28 @@ func New(config Config) (*Server, error) {
 		}
 	}
 
+	var webhookServer *webhook.Server
+	if address := config.Viper.GetString(config.Flag.Service.Webhook.Address); address != "" {
+		c := webhook.Config{
+			Logger:    config.Logger,
+			Mutator:   config.Service.ReleaseMutator,
+			Validator: config.Service.ReleaseValidator,
+
+			Address: address,
+			CrtFile: config.Viper.GetString(config.Flag.Service.Webhook.TLS.CrtFile),
+			KeyFile: config.Viper.GetString(config.Flag.Service.Webhook.TLS.KeyFile),
+		}
+
+		webhookServer, err = webhook.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	s := &Server{
-		logger:   config.Logger,
-		bootOnce: sync.Once{},
+		logger:        config.Logger,
+		webhookServer: webhookServer,
+		bootOnce:      sync.Once{},
 		config: microserver.Config{
 			Logger:      config.Logger,
 			ServiceName: project.Name(),
@@ -71,7 +96,14 @@ func New(config Config) (*Server, error) {
 
 func (s *Server) Boot() {
 	s.bootOnce.Do(func() {
-		// Insert here custom boot logic for server/endpoint/middleware if needed.
+		if s.webhookServer != nil {
+			go func() {
+				err := s.webhookServer.Boot(context.Background())
+				if err != nil {
+					panic(microerror.JSON(err))
+				}
+			}()
+		}
 	})
 }
 
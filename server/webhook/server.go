@@ -0,0 +1,178 @@
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/giantswarm/release-operator/v4/server/endpoint/mutate"
+	"github.com/giantswarm/release-operator/v4/server/endpoint/validate"
+)
+
+type Config struct {
+	Logger    micrologger.Logger
+	Mutator   admission.Handler
+	Validator admission.Handler
+
+	// Address is the address the admission webhooks are served on, e.g.
+	// 0.0.0.0:8443.
+	Address string
+	// CrtFile and KeyFile are the paths of the serving certificate and its
+	// private key. They are reloaded when cert-manager renews them.
+	CrtFile string
+	KeyFile string
+}
+
+// Server serves the admission webhooks for Release CRs with TLS, as required
+// by the Kubernetes API server. It is separate from the microkit server, which
+// serves healthz and metrics in plain HTTP.
+type Server struct {
+	logger micrologger.Logger
+
+	address    string
+	crtFile    string
+	keyFile    string
+	httpServer *http.Server
+}
+
+type endpoint interface {
+	Decoder() kithttp.DecodeRequestFunc
+	Encoder() kithttp.EncodeResponseFunc
+	Endpoint() kitendpoint.Endpoint
+	Method() string
+	Path() string
+}
+
+func New(config Config) (*Server, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Address == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Address must not be empty", config)
+	}
+	if config.CrtFile == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CrtFile must not be empty", config)
+	}
+	if config.KeyFile == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.KeyFile must not be empty", config)
+	}
+
+	var err error
+
+	var mutateEndpoint *mutate.Endpoint
+	{
+		c := mutate.Config{
+			Logger:  config.Logger,
+			Handler: config.Mutator,
+		}
+
+		mutateEndpoint, err = mutate.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var validateEndpoint *validate.Endpoint
+	{
+		c := validate.Config{
+			Logger:  config.Logger,
+			Handler: config.Validator,
+		}
+
+		validateEndpoint, err = validate.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	for _, e := range []endpoint{mutateEndpoint, validateEndpoint} {
+		mux.Handle(fmt.Sprintf("%s %s", e.Method(), e.Path()), kithttp.NewServer(e.Endpoint(), e.Decoder(), e.Encoder()))
+	}
+
+	s := &Server{
+		logger: config.Logger,
+
+		address: config.Address,
+		crtFile: config.CrtFile,
+		keyFile: config.KeyFile,
+		httpServer: &http.Server{
+			Handler:           mux,
+			IdleTimeout:       120 * time.Second,
+			ReadHeaderTimeout: 60 * time.Second,
+			ReadTimeout:       60 * time.Second,
+			WriteTimeout:      60 * time.Second,
+		},
+	}
+
+	return s, nil
+}
+
+// Boot listens on the configured address and serves the admission webhooks
+// until the context is cancelled.
+func (s *Server) Boot(ctx context.Context) error {
+	l, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = s.Serve(ctx, l)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// Serve serves the admission webhooks with TLS on the given listener until
+// the context is cancelled.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	watcher, err := certwatcher.New(s.crtFile, s.keyFile)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	go func() {
+		err := watcher.Start(ctx)
+		if err != nil {
+			s.logger.Errorf(ctx, err, "failed to watch webhook serving certificate")
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+
+		err := s.httpServer.Shutdown(context.Background())
+		if err != nil {
+			s.logger.Errorf(context.Background(), err, "failed to shut down webhook server")
+		}
+	}()
+
+	s.httpServer.TLSConfig = &tls.Config{
+		GetCertificate: watcher.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+
+	s.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("serving admission webhooks at https://%s", l.Addr()))
+
+	// The certificate is provided by the watcher.
+	err = s.httpServer.ServeTLS(l, "", "")
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/giantswarm/release-operator/v4/server/endpoint/validate"
+)
+
+func Test_Server_TLS(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	crtFile, keyFile, pool := writeTestCertificate(t)
+
+	s, err := New(Config{
+		Logger: microloggertest.New(),
+		Mutator: admission.HandlerFunc(func(ctx context.Context, req admission.Request) admission.Response {
+			return admission.Allowed("")
+		}),
+		Validator: admission.HandlerFunc(func(ctx context.Context, req admission.Request) admission.Response {
+			return admission.Denied("rejected by test")
+		}),
+
+		Address: "127.0.0.1:0",
+		CrtFile: crtFile,
+		KeyFile: keyFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(ctx, l)
+	}()
+
+	review := admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "admission.k8s.io/v1",
+			Kind:       "AdmissionReview",
+		},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       types.UID("abc12"),
+			Operation: admissionv1.Create,
+		},
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:    pool,
+				MinVersion: tls.VersionTLS12,
+			},
+		},
+	}
+
+	res, err := client.Post("https://"+l.Addr().String()+validate.Path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.TLS == nil || !res.TLS.HandshakeComplete {
+		t.Fatalf("expected TLS handshake to be complete")
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var response admissionv1.AdmissionReview
+	err = json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Response == nil || response.Response.UID != review.Request.UID || response.Response.Allowed {
+		t.Fatalf("expected denied response for %#q got %#v", review.Request.UID, response.Response)
+	}
+
+	// Plain HTTP is not served on the webhook listener.
+	plain, err := (&http.Client{Timeout: 5 * time.Second}).Post("http://"+l.Addr().String()+validate.Path, "application/json", bytes.NewReader(body))
+	if err == nil {
+		plain.Body.Close()
+		if plain.StatusCode == http.StatusOK {
+			t.Fatalf("expected plain HTTP request to fail")
+		}
+	}
+
+	cancel()
+
+	err = <-served
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeTestCertificate writes a self-signed serving certificate for
+// 127.0.0.1 and returns the paths of the certificate and key files and a pool
+// trusting it.
+func writeTestCertificate(t *testing.T) (string, string, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "release-operator"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	crtFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+
+	err = os.WriteFile(crtFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return crtFile, keyFile, pool
+}
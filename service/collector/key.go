@@ -1,8 +1,23 @@
 package collector
 
 const (
-	labelInUse = "inUse"
-	labelName  = "name"
-	labelState = "state"
-	labelReady = "ready"
+	labelAction    = "action"
+	labelAppStatus = "appStatus"
+	labelComponent = "component"
+	labelInUse     = "inUse"
+	labelKind      = "kind"
+	labelName      = "name"
+	labelState     = "state"
+	labelReady     = "ready"
+	labelVersion   = "version"
+)
+
+const (
+	actionCreate = "create"
+	actionDelete = "delete"
+)
+
+const (
+	kindApp    = "App"
+	kindConfig = "Config"
 )
@@ -3,13 +3,20 @@ package collector
 import (
 	"context"
 	"strconv"
+	"time"
 
+	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	corev1alpha1 "github.com/giantswarm/config-controller/api/v1alpha1"
 	"github.com/giantswarm/k8sclient/v7/pkg/k8sclient"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/giantswarm/release-operator/v4/api/v1alpha1"
+	"github.com/giantswarm/release-operator/v4/pkg/project"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
 )
 
 const (
@@ -33,16 +40,82 @@ var (
 		},
 		nil,
 	)
+	ReleaseComponentDesc *prometheus.Desc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "component_deployed"),
+		"Whether the App of a component deployed by release-operator is deployed, by the app status reported in the Release status.",
+		[]string{
+			labelName,
+			labelComponent,
+			labelVersion,
+			labelAppStatus,
+		},
+		nil,
+	)
+	ReleaseClustersDesc *prometheus.Desc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "clusters"),
+		"Number of clusters using a Release.",
+		[]string{
+			labelName,
+		},
+		nil,
+	)
+	ReleaseEndOfLifeDesc *prometheus.Desc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "end_of_life_seconds"),
+		"Seconds until the end of life date of a Release. Negative once it passed.",
+		[]string{
+			labelName,
+		},
+		nil,
+	)
+	ReleaseEndOfLifeClustersDesc *prometheus.Desc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "end_of_life_clusters"),
+		"Number of clusters still using a Release which reached its end of life.",
+		[]string{
+			labelName,
+		},
+		nil,
+	)
+	ReleaseAgeDesc *prometheus.Desc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "age_seconds"),
+		"Seconds since the date a Release became active.",
+		[]string{
+			labelName,
+		},
+		nil,
+	)
+	ManagedDesc *prometheus.Desc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "managed_resources"),
+		"Number of App and Config CRs managed by release-operator.",
+		[]string{
+			labelKind,
+		},
+		nil,
+	)
+	PendingDesc *prometheus.Desc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "pending_changes"),
+		"Number of App and Config CRs release-operator still has to create or delete to match the Releases.",
+		[]string{
+			labelKind,
+			labelAction,
+		},
+		nil,
+	)
 )
 
 type ReleaseCollector struct {
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
+
+	skipConfigs bool
 }
 
 type ReleaseCollectorConfig struct {
 	K8sClient k8sclient.Interface
 	Logger    micrologger.Logger
+
+	// SkipConfigs makes the collector not list Config CRs, as their CRD may
+	// not be installed when their generation is skipped.
+	SkipConfigs bool
 }
 
 func NewReleaseCollector(config ReleaseCollectorConfig) (*ReleaseCollector, error) {
@@ -56,6 +129,8 @@ func NewReleaseCollector(config ReleaseCollectorConfig) (*ReleaseCollector, erro
 	rc := &ReleaseCollector{
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
+
+		skipConfigs: config.SkipConfigs,
 	}
 
 	return rc, nil
@@ -66,7 +141,15 @@ func (r *ReleaseCollector) Collect(ch chan<- prometheus.Metric) error {
 
 	r.logger.LogCtx(ctx, "level", "debug", "message", "collecting metrics")
 
-	err := r.collectReleaseStatus(ctx, ch)
+	var releases v1alpha1.ReleaseList
+	err := r.k8sClient.CtrlClient().List(ctx, &releases)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.collectReleaseStatus(releases, ch)
+
+	err = r.collectManagedResources(ctx, releases, ch)
 	if err != nil {
 		return microerror.Mask(err)
 	}
@@ -77,15 +160,18 @@ func (r *ReleaseCollector) Collect(ch chan<- prometheus.Metric) error {
 
 func (r *ReleaseCollector) Describe(ch chan<- *prometheus.Desc) error {
 	ch <- ReleaseDesc
+	ch <- ReleaseComponentDesc
+	ch <- ReleaseClustersDesc
+	ch <- ReleaseEndOfLifeDesc
+	ch <- ReleaseEndOfLifeClustersDesc
+	ch <- ReleaseAgeDesc
+	ch <- ManagedDesc
+	ch <- PendingDesc
 	return nil
 }
 
-func (r *ReleaseCollector) collectReleaseStatus(ctx context.Context, ch chan<- prometheus.Metric) error {
-	var releases v1alpha1.ReleaseList
-	err := r.k8sClient.CtrlClient().List(ctx, &releases)
-	if err != nil {
-		return microerror.Mask(err)
-	}
+func (r *ReleaseCollector) collectReleaseStatus(releases v1alpha1.ReleaseList, ch chan<- prometheus.Metric) {
+	now := time.Now()
 
 	for _, release := range releases.Items {
 		ch <- prometheus.MustNewConstMetric(
@@ -97,7 +183,151 @@ func (r *ReleaseCollector) collectReleaseStatus(ctx context.Context, ch chan<- p
 			strconv.FormatBool(release.Status.Ready),
 			strconv.FormatBool(release.Status.InUse),
 		)
+
+		for _, component := range release.Status.Components {
+			var deployed float64
+			if component.AppStatus == key.AppStatusDeployed {
+				deployed = 1
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				ReleaseComponentDesc,
+				prometheus.GaugeValue,
+				deployed,
+				release.Name,
+				component.Name,
+				component.Version,
+				component.AppStatus,
+			)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			ReleaseClustersDesc,
+			prometheus.GaugeValue,
+			float64(release.Status.ClusterCount),
+			release.Name,
+		)
+
+		if release.Spec.EndOfLifeDate != nil {
+			ch <- prometheus.MustNewConstMetric(
+				ReleaseEndOfLifeDesc,
+				prometheus.GaugeValue,
+				release.Spec.EndOfLifeDate.Sub(now).Seconds(),
+				release.Name,
+			)
+		}
+
+		if key.EndOfLifeReached(release, now) {
+			ch <- prometheus.MustNewConstMetric(
+				ReleaseEndOfLifeClustersDesc,
+				prometheus.GaugeValue,
+				float64(release.Status.ClusterCount),
+				release.Name,
+			)
+		}
+
+		if release.Spec.Date != nil {
+			ch <- prometheus.MustNewConstMetric(
+				ReleaseAgeDesc,
+				prometheus.GaugeValue,
+				now.Sub(release.Spec.Date.Time).Seconds(),
+				release.Name,
+			)
+		}
 	}
+}
+
+func (r *ReleaseCollector) collectManagedResources(ctx context.Context, releases v1alpha1.ReleaseList, ch chan<- prometheus.Metric) error {
+	managedBy := &client.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{
+			key.LabelManagedBy: project.Name(),
+		}),
+	}
+
+	var apps appv1alpha1.AppList
+	{
+		err := r.k8sClient.CtrlClient().List(ctx, &apps, managedBy)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	var configs corev1alpha1.ConfigList
+	if !r.skipConfigs {
+		err := r.k8sClient.CtrlClient().List(ctx, &configs, managedBy)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(ManagedDesc, prometheus.GaugeValue, float64(len(apps.Items)), kindApp)
+	ch <- prometheus.MustNewConstMetric(ManagedDesc, prometheus.GaugeValue, float64(len(configs.Items)), kindConfig)
+
+	p := calculatePendingChanges(releases, apps.Items, configs.Items, r.skipConfigs)
+	ch <- prometheus.MustNewConstMetric(PendingDesc, prometheus.GaugeValue, float64(p.AppCreates), kindApp, actionCreate)
+	ch <- prometheus.MustNewConstMetric(PendingDesc, prometheus.GaugeValue, float64(p.AppDeletes), kindApp, actionDelete)
+	ch <- prometheus.MustNewConstMetric(PendingDesc, prometheus.GaugeValue, float64(p.ConfigCreates), kindConfig, actionCreate)
+	ch <- prometheus.MustNewConstMetric(PendingDesc, prometheus.GaugeValue, float64(p.ConfigDeletes), kindConfig, actionDelete)
 
 	return nil
 }
+
+// pendingChanges holds the number of Apps and Configs which still have to be
+// created or deleted.
+type pendingChanges struct {
+	AppCreates    int
+	AppDeletes    int
+	ConfigCreates int
+	ConfigDeletes int
+}
+
+// calculatePendingChanges compares the Apps and Configs desired by the given
+// releases with the existing ones by name, the same way the release set
+// controller selects the releases it deploys. Creates which are held back,
+// e.g. by dependencies or missing configuration, are counted as pending too.
+func calculatePendingChanges(releases v1alpha1.ReleaseList, apps []appv1alpha1.App, configs []corev1alpha1.Config, skipConfigs bool) pendingChanges {
+	releases = key.ExcludeDeletedRelease(releases)
+	releases = key.ExcludeUnusedDeprecatedReleases(releases)
+
+	desiredApps := map[string]bool{}
+	desiredConfigs := map[string]bool{}
+	for name, component := range key.ExtractComponents(releases) {
+		desiredApps[name] = true
+		if !skipConfigs && !component.SkipConfig {
+			desiredConfigs[key.BuildConfigName(component)] = true
+		}
+	}
+	for name := range key.ExtractApps(releases) {
+		desiredApps[name] = true
+	}
+
+	var p pendingChanges
+
+	existingApps := map[string]bool{}
+	for _, app := range apps {
+		existingApps[app.Name] = true
+		if !desiredApps[app.Name] {
+			p.AppDeletes++
+		}
+	}
+	for name := range desiredApps {
+		if !existingApps[name] {
+			p.AppCreates++
+		}
+	}
+
+	existingConfigs := map[string]bool{}
+	for _, config := range configs {
+		existingConfigs[config.Name] = true
+		if !desiredConfigs[config.Name] {
+			p.ConfigDeletes++
+		}
+	}
+	for name := range desiredConfigs {
+		if !existingConfigs[name] {
+			p.ConfigCreates++
+		}
+	}
+
+	return p
+}
@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+
+	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	corev1alpha1 "github.com/giantswarm/config-controller/api/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/release-operator/v4/api/v1alpha1"
+)
+
+func Test_calculatePendingChanges(t *testing.T) {
+	releases := v1alpha1.ReleaseList{
+		Items: []v1alpha1.Release{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "v1.0.0",
+				},
+				Spec: v1alpha1.ReleaseSpec{
+					Apps: []v1alpha1.ReleaseSpecApp{
+						{Name: "bundle", ReleaseOperatorDeploy: true, Version: "1.0.0"},
+						{Name: "ignored", Version: "1.0.0"},
+					},
+					Components: []v1alpha1.ReleaseSpecComponent{
+						{Name: "app-operator", ReleaseOperatorDeploy: true, Version: "1.0.0"},
+						{Name: "cert-operator", ReleaseOperatorDeploy: true, SkipConfig: true, Version: "1.0.0"},
+						{Name: "ignored", Version: "1.0.0"},
+					},
+					State: v1alpha1.StateActive,
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		apps            []appv1alpha1.App
+		configs         []corev1alpha1.Config
+		skipConfigs     bool
+		expectedPending pendingChanges
+	}{
+		{
+			name: "case 0: nothing deployed yet",
+			expectedPending: pendingChanges{
+				AppCreates:    3,
+				ConfigCreates: 1,
+			},
+		},
+		{
+			name: "case 1: everything deployed and obsolete resources left",
+			apps: []appv1alpha1.App{
+				{ObjectMeta: metav1.ObjectMeta{Name: "app-operator-1.0.0"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "app-operator-0.9.0"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "bundle-1.0.0"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "cert-operator-1.0.0"}},
+			},
+			configs: []corev1alpha1.Config{
+				{ObjectMeta: metav1.ObjectMeta{Name: "app-operator-1.0.0"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "cert-operator-1.0.0"}},
+			},
+			expectedPending: pendingChanges{
+				AppDeletes:    1,
+				ConfigDeletes: 1,
+			},
+		},
+		{
+			name:        "case 2: no configs are pending when skipped operator wide",
+			skipConfigs: true,
+			expectedPending: pendingChanges{
+				AppCreates: 3,
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			result := calculatePendingChanges(releases, tc.apps, tc.configs, tc.skipConfigs)
+
+			if !cmp.Equal(result, tc.expectedPending) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedPending, result))
+			}
+		})
+	}
+}
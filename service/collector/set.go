@@ -10,6 +10,8 @@ import (
 type SetConfig struct {
 	K8sClient k8sclient.Interface
 	Logger    micrologger.Logger
+
+	SkipConfigs bool
 }
 
 // Set is basically only a wrapper for the operator's collector implementations.
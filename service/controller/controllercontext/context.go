@@ -0,0 +1,46 @@
+package controllercontext
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+
+	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
+)
+
+type contextKey string
+
+const controllerKey contextKey = "controller"
+
+// Context carries the state computed once per reconciliation of the release
+// set and shared by all of its resources.
+type Context struct {
+	// Releases are the releases the components and apps below are extracted
+	// from.
+	Releases []releasev1alpha1.Release
+	// Components are the components of all releases which should be deployed,
+	// keyed by their App name.
+	Components map[string]releasev1alpha1.ReleaseSpecComponent
+	// ComponentDependencies are the App names every component depends on,
+	// keyed by its App name.
+	ComponentDependencies map[string][]string
+	// Apps are the release apps of all releases which should be deployed,
+	// keyed by their App name.
+	Apps map[string]releasev1alpha1.ReleaseSpecApp
+	// AppDependencies are the App names every release app depends on, keyed
+	// by its App name.
+	AppDependencies map[string][]string
+}
+
+func NewContext(ctx context.Context, c Context) context.Context {
+	return context.WithValue(ctx, controllerKey, &c)
+}
+
+func FromContext(ctx context.Context) (*Context, error) {
+	c, ok := ctx.Value(controllerKey).(*Context)
+	if !ok {
+		return nil, microerror.Mask(notFoundError)
+	}
+
+	return c, nil
+}
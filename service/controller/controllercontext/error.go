@@ -0,0 +1,14 @@
+package controllercontext
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var notFoundError = &microerror.Error{
+	Kind: "notFoundError",
+}
+
+// IsNotFound asserts notFoundError.
+func IsNotFound(err error) bool {
+	return microerror.Cause(err) == notFoundError
+}
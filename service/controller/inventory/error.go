@@ -0,0 +1,23 @@
+package inventory
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var notSyncedError = &microerror.Error{
+	Kind: "notSyncedError",
+}
+
+// IsNotSynced asserts notSyncedError.
+func IsNotSynced(err error) bool {
+	return microerror.Cause(err) == notSyncedError
+}
@@ -0,0 +1,447 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/k8sclient/v7/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// DefaultDiscoveryInterval is how often the sources served by the API
+	// are discovered again when no interval is configured.
+	DefaultDiscoveryInterval = time.Minute
+	// DefaultSyncTimeout is how long Boot waits for the first sync of all
+	// sources when no timeout is configured.
+	DefaultSyncTimeout = time.Minute
+)
+
+type Config struct {
+	K8sClient      k8sclient.Interface
+	Logger         micrologger.Logger
+	MetadataClient metadata.Interface
+
+	// DiscoveryInterval is how often the sources served by the API are
+	// discovered again after Boot. Defaults to DefaultDiscoveryInterval when
+	// zero.
+	DiscoveryInterval time.Duration
+	// Sources are the kinds of resources representing clusters. Defaults
+	// to DefaultSourceConfigs when empty. Configured sources which are not
+	// served by the API are reported as failures, default ones are skipped.
+	Sources []Source
+	// SyncTimeout is how long Boot waits for the first sync of the sources.
+	// Defaults to DefaultSyncTimeout when zero.
+	SyncTimeout time.Duration
+}
+
+// Inventory keeps an informer backed, in memory view of the clusters running
+// on the installation. It is shared by all reconciliations so that the number
+// of requests against the Kubernetes API does not grow with the number of
+// releases.
+type Inventory struct {
+	k8sClient      k8sclient.Interface
+	logger         micrologger.Logger
+	metadataClient metadata.Interface
+
+	discoveryInterval time.Duration
+	sources           []Source
+	sourcesConfigured bool
+	syncTimeout       time.Duration
+
+	mutex     sync.RWMutex
+	informers []*sourceInformer
+	// unserved describes the configured sources which are not served by
+	// the API.
+	unserved []string
+	synced   bool
+}
+
+type sourceInformer struct {
+	resource watchedResource
+	informer cache.SharedIndexInformer
+	// stop stops the informer once its resource is no longer served.
+	stop context.CancelFunc
+
+	mutex sync.Mutex
+	// failure is the last error listing or watching the source and
+	// failureResourceVersion the resource version the informer had synced
+	// at that time. The failure is resolved once a newer version is synced.
+	failure                error
+	failureResourceVersion string
+}
+
+// Cluster is a tenant cluster found on the installation.
+type Cluster struct {
+	ID               string
+	Namespace        string
+	OperatorVersion  string
+	ProviderOperator string
+	ReleaseVersion   string
+}
+
+// Snapshot is a point in time copy of the inventory.
+type Snapshot struct {
+	Clusters []Cluster
+	// Failures describes the sources which could not be listed or watched
+	// recently, have not been synced yet or are configured but not served by
+	// the API. Their clusters are the last ones known and may be outdated or
+	// missing.
+	Failures []string
+
+	kvmOperatorPods []metav1.PartialObjectMetadata
+}
+
+// Degraded returns whether clusters may be missing from the snapshot because
+// some sources are failing.
+func (s Snapshot) Degraded() bool {
+	return len(s.Failures) > 0
+}
+
+func New(config Config) (*Inventory, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.MetadataClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.MetadataClient must not be empty", config)
+	}
+
+	sources := config.Sources
+	if len(sources) == 0 {
+		var err error
+		sources, err = NewSources(DefaultSourceConfigs)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	discoveryInterval := config.DiscoveryInterval
+	if discoveryInterval == 0 {
+		discoveryInterval = DefaultDiscoveryInterval
+	}
+	syncTimeout := config.SyncTimeout
+	if syncTimeout == 0 {
+		syncTimeout = DefaultSyncTimeout
+	}
+
+	i := &Inventory{
+		k8sClient:      config.K8sClient,
+		logger:         config.Logger,
+		metadataClient: config.MetadataClient,
+
+		discoveryInterval: discoveryInterval,
+		sources:           sources,
+		sourcesConfigured: len(config.Sources) > 0,
+		syncTimeout:       syncTimeout,
+	}
+
+	return i, nil
+}
+
+// Boot starts an informer for every source served by the API and blocks until
+// all of them are synced, the sync timeout passed or the context is
+// cancelled. Sources which did not sync in time keep being retried in the
+// background and are reported as failures by Snapshot until they synced, so
+// that a single failing source does not keep the controllers from starting.
+// Sources without pinned version are watched in the version preferred by the
+// API. Sources whose resources are not installed are skipped. After Boot the
+// sources are discovered again every discovery interval until the context is
+// cancelled, so that resources installed or removed later on are watched or
+// no longer watched.
+func (i *Inventory) Boot(ctx context.Context) error {
+	err := i.discover(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	i.mutex.RLock()
+	informers := i.informers
+	i.mutex.RUnlock()
+
+	var hasSynced []cache.InformerSynced
+	for _, si := range informers {
+		hasSynced = append(hasSynced, si.informer.HasSynced)
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, i.syncTimeout)
+	defer cancel()
+
+	if !cache.WaitForCacheSync(syncCtx.Done(), hasSynced...) {
+		if ctx.Err() != nil {
+			return microerror.Maskf(notSyncedError, "context cancelled before informers synced")
+		}
+
+		var notSynced []string
+		for _, si := range informers {
+			if !si.informer.HasSynced() {
+				notSynced = append(notSynced, si.resource.String())
+			}
+		}
+		i.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("inventory sources %s not synced within %s, reporting them as failures until they are synced", strings.Join(notSynced, ", "), i.syncTimeout))
+	} else {
+		i.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("synced %d inventory sources", len(informers)))
+	}
+
+	i.mutex.Lock()
+	i.synced = true
+	i.mutex.Unlock()
+
+	go i.rediscover(ctx)
+
+	return nil
+}
+
+// Snapshot returns the clusters currently cached by the inventory. It does not
+// issue any requests against the Kubernetes API.
+func (i *Inventory) Snapshot(ctx context.Context) (Snapshot, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	if !i.synced {
+		return Snapshot{}, microerror.Maskf(notSyncedError, "inventory has not been synced yet")
+	}
+
+	var snapshot Snapshot
+	snapshot.Failures = append(snapshot.Failures, i.unserved...)
+	for _, si := range i.informers {
+		if err := si.failed(); err != nil {
+			snapshot.Failures = append(snapshot.Failures, fmt.Sprintf("%s: %s", si.resource, err))
+		} else if !si.informer.HasSynced() {
+			snapshot.Failures = append(snapshot.Failures, fmt.Sprintf("%s: not synced yet", si.resource))
+		}
+
+		for _, obj := range si.informer.GetStore().List() {
+			m, ok := obj.(*metav1.PartialObjectMetadata)
+			if !ok {
+				continue
+			}
+
+			if si.resource.Source != nil {
+				snapshot.Clusters = append(snapshot.Clusters, si.resource.Source.ToCluster(*m))
+			} else {
+				snapshot.kvmOperatorPods = append(snapshot.kvmOperatorPods, *m)
+			}
+		}
+	}
+
+	sort.Slice(snapshot.Clusters, func(a, b int) bool {
+		if snapshot.Clusters[a].Namespace != snapshot.Clusters[b].Namespace {
+			return snapshot.Clusters[a].Namespace < snapshot.Clusters[b].Namespace
+		}
+		return snapshot.Clusters[a].ID < snapshot.Clusters[b].ID
+	})
+
+	return snapshot, nil
+}
+
+// rediscover discovers the sources every discovery interval until the
+// context is cancelled. Failed discoveries keep the current informers.
+func (i *Inventory) rediscover(ctx context.Context) {
+	ticker := time.NewTicker(i.discoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := i.discover(ctx)
+			if err != nil {
+				i.logger.Errorf(ctx, err, "failed to discover inventory sources")
+			}
+		}
+	}
+}
+
+// discover resolves the resources of all sources served by the API. It starts
+// an informer for every resource not watched yet and stops the informers of
+// resources which are no longer served.
+func (i *Inventory) discover(ctx context.Context) error {
+	watched, unserved, err := i.watchedResources(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	current := map[schema.GroupVersionResource]*sourceInformer{}
+	for _, si := range i.informers {
+		current[si.resource.Resource] = si
+	}
+
+	var informers []*sourceInformer
+	var started []*sourceInformer
+	for _, w := range watched {
+		si, ok := current[w.Resource]
+		if ok {
+			delete(current, w.Resource)
+		} else {
+			si, err = i.startInformer(ctx, w)
+			if err != nil {
+				for _, s := range started {
+					s.stop()
+				}
+				return microerror.Mask(err)
+			}
+			started = append(started, si)
+
+			if i.synced {
+				i.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("started watching inventory source %#q as it is served by the API now", w))
+			}
+		}
+
+		informers = append(informers, si)
+	}
+
+	for _, si := range current {
+		si.stop()
+
+		i.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("stopped watching inventory source %#q as it is no longer served by the API", si.resource))
+	}
+
+	i.informers = informers
+	i.unserved = unserved
+
+	return nil
+}
+
+// startInformer starts an informer caching the metadata of the given
+// resource. It runs until the context is cancelled or it is stopped.
+func (i *Inventory) startInformer(ctx context.Context, w watchedResource) (*sourceInformer, error) {
+	labelSelector := w.LabelSelector
+	informer := metadatainformer.NewFilteredMetadataInformer(i.metadataClient, w.Resource, metav1.NamespaceAll, 0, cache.Indexers{}, func(options *metav1.ListOptions) {
+		options.LabelSelector = labelSelector
+	}).Informer()
+
+	si := &sourceInformer{resource: w, informer: informer}
+	err := informer.SetWatchErrorHandlerWithContext(func(ctx context.Context, r *cache.Reflector, err error) {
+		si.recordFailure(err)
+		cache.DefaultWatchErrorHandler(ctx, r, err)
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var informerCtx context.Context
+	informerCtx, si.stop = context.WithCancel(ctx)
+
+	go informer.Run(informerCtx.Done())
+
+	return si, nil
+}
+
+func (si *sourceInformer) recordFailure(err error) {
+	si.mutex.Lock()
+	defer si.mutex.Unlock()
+
+	si.failure = err
+	si.failureResourceVersion = si.informer.LastSyncResourceVersion()
+}
+
+// failed returns the last error listing or watching the source unless the
+// informer synced a newer resource version since.
+func (si *sourceInformer) failed() error {
+	si.mutex.Lock()
+	defer si.mutex.Unlock()
+
+	if si.failure != nil && si.informer.LastSyncResourceVersion() != si.failureResourceVersion {
+		si.failure = nil
+	}
+
+	return si.failure
+}
+
+// watchedResources resolves the resources of all sources served by the API.
+// Configured sources which are not served are described by the returned
+// failures.
+func (i *Inventory) watchedResources(ctx context.Context) ([]watchedResource, []string, error) {
+	groups, err := i.k8sClient.K8sClient().Discovery().ServerGroups()
+	if err != nil {
+		return nil, nil, microerror.Mask(err)
+	}
+
+	preferredVersions := map[string]string{}
+	for _, g := range groups.Groups {
+		preferredVersions[g.Name] = g.PreferredVersion.Version
+	}
+
+	var watched []watchedResource
+	var unserved []string
+	for _, s := range i.sources {
+		gr := s.GroupResource()
+
+		version := s.Version()
+		if version == "" {
+			version = preferredVersions[gr.Group]
+		}
+		if version == "" {
+			i.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("skipping %#q because its group is not served by the API", gr))
+			if i.sourcesConfigured {
+				unserved = append(unserved, fmt.Sprintf("%s: group not served by the API", gr))
+			}
+			continue
+		}
+
+		gvr := gr.WithVersion(version)
+		served, err := i.isServed(gvr)
+		if err != nil {
+			return nil, nil, microerror.Mask(err)
+		}
+		if !served {
+			i.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("skipping %#q because it is not served by the API in version %#q", gr, version))
+			if i.sourcesConfigured {
+				unserved = append(unserved, fmt.Sprintf("%s: not served by the API", gvr))
+			}
+			continue
+		}
+
+		watched = append(watched, watchedResource{
+			Resource: gvr,
+			Source:   s,
+		})
+	}
+
+	served, err := i.isServed(kvmOperatorPodResource.Resource)
+	if err != nil {
+		return nil, nil, microerror.Mask(err)
+	}
+	if served {
+		watched = append(watched, kvmOperatorPodResource)
+	} else {
+		i.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("skipping %#q because it is not served by the API", kvmOperatorPodResource))
+	}
+
+	return watched, unserved, nil
+}
+
+func (i *Inventory) isServed(gvr schema.GroupVersionResource) (bool, error) {
+	resources, err := i.k8sClient.K8sClient().Discovery().ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	for _, r := range resources.APIResources {
+		if r.Name == gvr.Resource {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
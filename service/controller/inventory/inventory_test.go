@@ -0,0 +1,596 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/k8sclient/v7/pkg/k8sclienttest"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
+)
+
+func Test_Inventory_Snapshot(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objects := []runtime.Object{
+		newObject("cluster.x-k8s.io/v1alpha3", "Cluster", "org-acme", "abc12", map[string]string{
+			"release.giantswarm.io/version": "11.3.0",
+		}),
+		newObject("infrastructure.giantswarm.io/v1alpha3", "AWSCluster", "default", "def34", map[string]string{
+			"aws-operator.giantswarm.io/version": "8.7.0",
+			"release.giantswarm.io/version":      "11.2.0",
+		}),
+		// KVMConfigs are not served by the fake API and must be ignored.
+		newObject("provider.giantswarm.io/v1alpha1", "KVMConfig", "default", "ghi56", nil),
+		newObject("v1", "Pod", "jkl78", "master-jkl78", map[string]string{
+			PodWatcherLabel:         key.ProviderOperatorKVM,
+			KVMOperatorVersionLabel: "3.12.0",
+		}),
+	}
+
+	scheme := metadatafake.NewTestScheme()
+	err := metav1.AddMetaToScheme(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadataClient := metadatafake.NewSimpleMetadataClient(scheme, objects...)
+
+	inventory := newTestInventory(t, metadataClient, []*metav1.APIResourceList{
+		{GroupVersion: "cluster.x-k8s.io/v1alpha3", APIResources: []metav1.APIResource{{Name: "clusters", Kind: "Cluster"}}},
+		{GroupVersion: "infrastructure.giantswarm.io/v1alpha3", APIResources: []metav1.APIResource{{Name: "awsclusters", Kind: "AWSCluster"}}},
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod"}}},
+	})
+
+	_, err = inventory.Snapshot(ctx)
+	if !IsNotSynced(err) {
+		t.Fatalf("expected %#q got %#v", notSyncedError, err)
+	}
+
+	err = inventory.Boot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedClusters := []Cluster{
+		{
+			ID:               "def34",
+			Namespace:        "default",
+			OperatorVersion:  "8.7.0",
+			ProviderOperator: key.ProviderOperatorAWS,
+			ReleaseVersion:   "11.2.0",
+		},
+		{
+			ID:             "abc12",
+			Namespace:      "org-acme",
+			ReleaseVersion: "11.3.0",
+		},
+	}
+
+	actionsAfterBoot := len(metadataClient.Actions())
+
+	// Simulate the reconciliation of many releases, none of which may
+	// cause additional requests against the API.
+	for i := 0; i < 150; i++ {
+		snapshot, err := inventory.Snapshot(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.Equal(snapshot.Clusters, expectedClusters) {
+			t.Fatalf("\n\n%s\n", cmp.Diff(expectedClusters, snapshot.Clusters))
+		}
+	}
+
+	if !cmp.Equal(len(metadataClient.Actions()), actionsAfterBoot) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(actionsAfterBoot, len(metadataClient.Actions())))
+	}
+
+	var lists int
+	for _, action := range metadataClient.Actions() {
+		if action.GetVerb() == "list" {
+			lists++
+		}
+	}
+	// One list per served source.
+	if !cmp.Equal(lists, 3) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(3, lists))
+	}
+}
+
+func Test_Inventory_Snapshot_failures(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objects := []runtime.Object{
+		newObject("cluster.x-k8s.io/v1alpha3", "Cluster", "org-acme", "abc12", map[string]string{
+			"release.giantswarm.io/version": "11.3.0",
+		}),
+		newObject("infrastructure.giantswarm.io/v1alpha3", "AWSCluster", "default", "def34", map[string]string{
+			"aws-operator.giantswarm.io/version": "8.7.0",
+			"release.giantswarm.io/version":      "11.2.0",
+		}),
+	}
+
+	scheme := metadatafake.NewTestScheme()
+	err := metav1.AddMetaToScheme(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadataClient := metadatafake.NewSimpleMetadataClient(scheme, objects...)
+
+	inventory := newTestInventory(t, metadataClient, []*metav1.APIResourceList{
+		{GroupVersion: "cluster.x-k8s.io/v1alpha3", APIResources: []metav1.APIResource{{Name: "clusters", Kind: "Cluster"}}},
+		{GroupVersion: "infrastructure.giantswarm.io/v1alpha3", APIResources: []metav1.APIResource{{Name: "awsclusters", Kind: "AWSCluster"}}},
+	})
+
+	err = inventory.Boot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Watching AWS clusters fails while CAPI clusters are still watched.
+	inventory.informers[1].recordFailure(errors.New("connection refused"))
+
+	snapshot, err := inventory.Snapshot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedFailures := []string{"infrastructure.giantswarm.io/v1alpha3, Resource=awsclusters: connection refused"}
+	if !cmp.Equal(snapshot.Failures, expectedFailures) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(expectedFailures, snapshot.Failures))
+	}
+	if !snapshot.Degraded() {
+		t.Fatalf("expected snapshot to be degraded")
+	}
+	// The last known clusters of the failing source are kept.
+	if !cmp.Equal(len(snapshot.Clusters), 2) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(2, len(snapshot.Clusters)))
+	}
+
+	// The failure is resolved once the informer synced a newer resource
+	// version than the one it had synced when failing.
+	inventory.informers[1].failureResourceVersion = "outdated"
+
+	snapshot, err = inventory.Snapshot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Degraded() {
+		t.Fatalf("expected snapshot not to be degraded, got %#v", snapshot.Failures)
+	}
+}
+
+func Test_Inventory_Boot_failingSource(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objects := []runtime.Object{
+		newObject("cluster.x-k8s.io/v1alpha3", "Cluster", "org-acme", "abc12", map[string]string{
+			"release.giantswarm.io/version": "11.3.0",
+		}),
+	}
+
+	scheme := metadatafake.NewTestScheme()
+	err := metav1.AddMetaToScheme(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadataClient := metadatafake.NewSimpleMetadataClient(scheme, objects...)
+	// Listing AWS clusters fails from the start, e.g. because of missing
+	// permissions.
+	metadataClient.PrependReactor("list", "awsclusters", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+
+	inventory := newTestInventory(t, metadataClient, []*metav1.APIResourceList{
+		{GroupVersion: "cluster.x-k8s.io/v1alpha3", APIResources: []metav1.APIResource{{Name: "clusters", Kind: "Cluster"}}},
+		{GroupVersion: "infrastructure.giantswarm.io/v1alpha3", APIResources: []metav1.APIResource{{Name: "awsclusters", Kind: "AWSCluster"}}},
+	})
+	inventory.syncTimeout = 500 * time.Millisecond
+
+	// Boot returns once the sync timeout passed so that the controllers
+	// can start.
+	err = inventory.Boot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := inventory.Snapshot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !snapshot.Degraded() {
+		t.Fatalf("expected snapshot to be degraded")
+	}
+	if !cmp.Equal(len(snapshot.Failures), 1) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(1, len(snapshot.Failures)))
+	}
+	if !strings.HasPrefix(snapshot.Failures[0], "infrastructure.giantswarm.io/v1alpha3, Resource=awsclusters: ") {
+		t.Fatalf("expected failure of awsclusters got %#q", snapshot.Failures[0])
+	}
+
+	// The clusters of the synced sources are still found.
+	expectedClusters := []Cluster{
+		{
+			ID:             "abc12",
+			Namespace:      "org-acme",
+			ReleaseVersion: "11.3.0",
+		},
+	}
+	if !cmp.Equal(snapshot.Clusters, expectedClusters) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(expectedClusters, snapshot.Clusters))
+	}
+}
+
+func Test_Inventory_Snapshot_sources(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objects := []runtime.Object{
+		newObject("cluster.x-k8s.io/v1beta1", "Cluster", "org-acme", "abc12", map[string]string{
+			"release.giantswarm.io/version": "20.0.0",
+		}),
+		newObject("provider.giantswarm.io/v1alpha1", "AzureConfig", "default", "def34", map[string]string{
+			"azure-operator.giantswarm.io/version": "5.0.0",
+			"release.giantswarm.io/version":        "14.1.0",
+		}),
+	}
+
+	scheme := metadatafake.NewTestScheme()
+	err := metav1.AddMetaToScheme(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadataClient := metadatafake.NewSimpleMetadataClient(scheme, objects...)
+
+	sources, err := NewSources([]LabelSourceConfig{
+		{
+			Group:        "cluster.x-k8s.io",
+			Resource:     "clusters",
+			ReleaseLabel: "release.giantswarm.io/version",
+		},
+		{
+			Group:         "provider.giantswarm.io",
+			Resource:      "azureconfigs",
+			ReleaseLabel:  "release.giantswarm.io/version",
+			OperatorLabel: "azure-operator.giantswarm.io/version",
+			OperatorName:  key.ProviderOperatorAzure,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first version of a group is the one preferred by the fake API.
+	inventory := newTestInventory(t, metadataClient, []*metav1.APIResourceList{
+		{GroupVersion: "cluster.x-k8s.io/v1beta1", APIResources: []metav1.APIResource{{Name: "clusters", Kind: "Cluster"}, {Name: "clusters/status", Kind: "Cluster"}}},
+		{GroupVersion: "cluster.x-k8s.io/v1alpha3", APIResources: []metav1.APIResource{{Name: "clusters", Kind: "Cluster"}}},
+		{GroupVersion: "provider.giantswarm.io/v1alpha1", APIResources: []metav1.APIResource{{Name: "azureconfigs", Kind: "AzureConfig"}}},
+	}, sources...)
+
+	err = inventory.Boot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := inventory.Snapshot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedClusters := []Cluster{
+		{
+			ID:               "def34",
+			Namespace:        "default",
+			OperatorVersion:  "5.0.0",
+			ProviderOperator: key.ProviderOperatorAzure,
+			ReleaseVersion:   "14.1.0",
+		},
+		{
+			ID:             "abc12",
+			Namespace:      "org-acme",
+			ReleaseVersion: "20.0.0",
+		},
+	}
+	if !cmp.Equal(snapshot.Clusters, expectedClusters) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(expectedClusters, snapshot.Clusters))
+	}
+
+	var resources []string
+	for _, si := range inventory.informers {
+		resources = append(resources, si.resource.String())
+	}
+	expectedResources := []string{
+		"cluster.x-k8s.io/v1beta1, Resource=clusters",
+		"provider.giantswarm.io/v1alpha1, Resource=azureconfigs",
+	}
+	if !cmp.Equal(resources, expectedResources) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(expectedResources, resources))
+	}
+}
+
+func Test_Inventory_discover_removedResource(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objects := []runtime.Object{
+		newObject("cluster.x-k8s.io/v1alpha3", "Cluster", "org-acme", "abc12", map[string]string{
+			"release.giantswarm.io/version": "11.3.0",
+		}),
+		newObject("infrastructure.giantswarm.io/v1alpha3", "AWSCluster", "default", "def34", map[string]string{
+			"aws-operator.giantswarm.io/version": "8.7.0",
+			"release.giantswarm.io/version":      "11.2.0",
+		}),
+	}
+
+	scheme := metadatafake.NewTestScheme()
+	err := metav1.AddMetaToScheme(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadataClient := metadatafake.NewSimpleMetadataClient(scheme, objects...)
+
+	clusters := &metav1.APIResourceList{GroupVersion: "cluster.x-k8s.io/v1alpha3", APIResources: []metav1.APIResource{{Name: "clusters", Kind: "Cluster"}}}
+	awsClusters := &metav1.APIResourceList{GroupVersion: "infrastructure.giantswarm.io/v1alpha3", APIResources: []metav1.APIResource{{Name: "awsclusters", Kind: "AWSCluster"}}}
+
+	inventory := newTestInventory(t, metadataClient, []*metav1.APIResourceList{clusters, awsClusters})
+	discovery := inventory.k8sClient.K8sClient().Discovery().(*fakediscovery.FakeDiscovery)
+
+	err = inventory.Boot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The AWSCluster CRD is removed after Boot. Its informer keeps failing
+	// until the next discovery stops it.
+	discovery.Resources = []*metav1.APIResourceList{clusters}
+	kept := inventory.informers[0]
+	removed := inventory.informers[1]
+	removed.recordFailure(errors.New("the server could not find the requested resource"))
+
+	err = inventory.discover(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := inventory.Snapshot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Degraded() {
+		t.Fatalf("expected snapshot not to be degraded, got %#v", snapshot.Failures)
+	}
+	expectedClusters := []Cluster{
+		{
+			ID:             "abc12",
+			Namespace:      "org-acme",
+			ReleaseVersion: "11.3.0",
+		},
+	}
+	if !cmp.Equal(snapshot.Clusters, expectedClusters) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(expectedClusters, snapshot.Clusters))
+	}
+
+	if !cmp.Equal(len(inventory.informers), 1) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(1, len(inventory.informers)))
+	}
+	for deadline := time.Now().Add(5 * time.Second); !removed.informer.IsStopped(); {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected informer of removed resource to be stopped")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The AWSCluster CRD is installed again and watched once discovered.
+	discovery.Resources = []*metav1.APIResourceList{clusters, awsClusters}
+
+	err = inventory.discover(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(len(inventory.informers), 2) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(2, len(inventory.informers)))
+	}
+	if inventory.informers[0] != kept {
+		t.Fatalf("expected informer of clusters to be kept")
+	}
+}
+
+func Test_Inventory_Snapshot_unservedSources(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scheme := metadatafake.NewTestScheme()
+	err := metav1.AddMetaToScheme(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadataClient := metadatafake.NewSimpleMetadataClient(scheme)
+
+	sources, err := NewSources([]LabelSourceConfig{
+		{
+			Group:        "cluster.x-k8s.io",
+			Resource:     "clusters",
+			ReleaseLabel: "release.giantswarm.io/version",
+		},
+		{
+			Group:        "cluster.x-k8s.io",
+			Resource:     "klusters",
+			ReleaseLabel: "release.giantswarm.io/version",
+		},
+		{
+			Group:        "provider.giantswarm.io",
+			Resource:     "azureconfigs",
+			ReleaseLabel: "release.giantswarm.io/version",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inventory := newTestInventory(t, metadataClient, []*metav1.APIResourceList{
+		{GroupVersion: "cluster.x-k8s.io/v1beta1", APIResources: []metav1.APIResource{{Name: "clusters", Kind: "Cluster"}}},
+	}, sources...)
+
+	err = inventory.Boot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := inventory.Snapshot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Configured sources which are not served are failures, so that a typo
+	// in the configuration does not silently hide clusters.
+	expectedFailures := []string{
+		"cluster.x-k8s.io/v1beta1, Resource=klusters: not served by the API",
+		"azureconfigs.provider.giantswarm.io: group not served by the API",
+	}
+	if !cmp.Equal(snapshot.Failures, expectedFailures) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(expectedFailures, snapshot.Failures))
+	}
+}
+
+func Test_Snapshot_KVMOperatorPods(t *testing.T) {
+	testCases := []struct {
+		name            string
+		pods            []metav1.PartialObjectMetadata
+		operatorVersion string
+		expectedValue   bool
+	}{
+		{
+			name: "case 0: pre-k8s 1.18 kvm-operator with matching pod",
+			pods: []metav1.PartialObjectMetadata{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							PodWatcherLabel: "kvm-operator",
+						},
+						Annotations: map[string]string{
+							KVMVersionBundleVersionAnnotation: "1.0.0",
+						},
+					},
+				},
+			},
+			operatorVersion: "1.0.0",
+			expectedValue:   true,
+		},
+		{
+			name: "case 1: k8s 1.18 kvm-operator with matching pod",
+			pods: []metav1.PartialObjectMetadata{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							PodWatcherLabel:         "kvm-operator",
+							KVMOperatorVersionLabel: "1.0.0",
+						},
+					},
+				},
+			},
+			operatorVersion: "1.0.0",
+			expectedValue:   true,
+		},
+		{
+			name: "case 2: pre-k8s 1.18 kvm-operator without matching pod",
+			pods: []metav1.PartialObjectMetadata{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							PodWatcherLabel: "kvm-operator",
+						},
+						Annotations: map[string]string{
+							KVMVersionBundleVersionAnnotation: "1.0.1",
+						},
+					},
+				},
+			},
+			operatorVersion: "1.0.0",
+			expectedValue:   false,
+		},
+		{
+			name: "case 3: k8s 1.18 kvm-operator without matching pod",
+			pods: []metav1.PartialObjectMetadata{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							PodWatcherLabel:         "kvm-operator",
+							KVMOperatorVersionLabel: "1.0.1",
+						},
+					},
+				},
+			},
+			operatorVersion: "1.0.0",
+			expectedValue:   false,
+		},
+		{
+			name:            "case 4: no pods",
+			pods:            nil,
+			operatorVersion: "1.0.0",
+			expectedValue:   false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			snapshot := Snapshot{
+				kvmOperatorPods: tc.pods,
+			}
+
+			result := len(snapshot.KVMOperatorPods(tc.operatorVersion)) > 0
+			if !cmp.Equal(result, tc.expectedValue) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedValue, result))
+			}
+		})
+	}
+}
+
+func newTestInventory(t *testing.T, metadataClient *metadatafake.FakeMetadataClient, resources []*metav1.APIResourceList, sources ...Source) *Inventory {
+	k8sClient := fake.NewSimpleClientset()
+	k8sClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = resources
+
+	inventory, err := New(Config{
+		K8sClient: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+			K8sClient: k8sClient,
+		}),
+		Logger:         microloggertest.New(),
+		MetadataClient: metadataClient,
+		Sources:        sources,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return inventory
+}
+
+func newObject(apiVersion, kind, namespace, name string, labels map[string]string) *metav1.PartialObjectMetadata {
+	return &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: apiVersion,
+			Kind:       kind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    labels,
+		},
+	}
+}
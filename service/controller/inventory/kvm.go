@@ -0,0 +1,28 @@
+package inventory
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// from https://github.com/giantswarm/kvm-operator/blob/9dc5f0d8075731c600e2852b27e71dbc2e91015d/service/controller/key/key.go#L123
+	PodWatcherLabel = "kvm-operator.giantswarm.io/pod-watcher"
+	// from https://github.com/giantswarm/kvm-operator/blob/9dc5f0d8075731c600e2852b27e71dbc2e91015d/service/controller/key/key.go#L101
+	KVMVersionBundleVersionAnnotation = "kvm-operator.giantswarm.io/version-bundle"
+	// from https://github.com/giantswarm/kvm-operator/blob/eee64f540cae53d530628d50e54883b636d0693f/pkg/label/label.go#L17
+	KVMOperatorVersionLabel = "kvm-operator.giantswarm.io/version"
+)
+
+// KVMOperatorPods returns the kvm-operator pods which were created by the
+// given kvm-operator version and still need to be drained.
+func (s Snapshot) KVMOperatorPods(operatorVersion string) []metav1.PartialObjectMetadata {
+	var result []metav1.PartialObjectMetadata
+	for _, pod := range s.kvmOperatorPods {
+		if pod.Annotations[KVMVersionBundleVersionAnnotation] == operatorVersion ||
+			pod.Labels[KVMOperatorVersionLabel] == operatorVersion {
+			result = append(result, pod)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,165 @@
+package inventory
+
+import (
+	"fmt"
+
+	apiexlabels "github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
+)
+
+// Source is a kind of resource representing clusters. The inventory watches
+// the metadata of every source served by the API.
+type Source interface {
+	// GroupResource is the group and the plural resource name, e.g.
+	// clusters.
+	GroupResource() schema.GroupResource
+	// Version is the version of the resource to watch. When empty the
+	// version preferred by the API is watched.
+	Version() string
+	// ToCluster converts the metadata of a cached object into a Cluster.
+	ToCluster(m metav1.PartialObjectMetadata) Cluster
+}
+
+// LabelSourceConfig configures a LabelSource. It is decoded from the
+// operator configuration.
+type LabelSourceConfig struct {
+	Group string `json:"group"`
+	// Version is optional. The version preferred by the API is used when it
+	// is empty.
+	Version string `json:"version,omitempty"`
+	// Resource is the plural resource name, e.g. azureconfigs. It is the
+	// same name list and watch must be granted on.
+	Resource string `json:"resource"`
+	// ReleaseLabel is the label holding the release version of the cluster.
+	ReleaseLabel string `json:"releaseLabel"`
+	// OperatorLabel is the label holding the version of the provider
+	// operator which created the cluster, and OperatorName the name of that
+	// operator's component in releases. Both are optional and only needed
+	// for legacy clusters without release label.
+	OperatorLabel string `json:"operatorLabel,omitempty"`
+	OperatorName  string `json:"operatorName,omitempty"`
+}
+
+// LabelSource is a Source reading the release and provider operator version
+// of clusters from their labels.
+type LabelSource struct {
+	groupResource schema.GroupResource
+	version       string
+	releaseLabel  string
+	operatorLabel string
+	operatorName  string
+}
+
+func NewLabelSource(config LabelSourceConfig) (*LabelSource, error) {
+	if config.Resource == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Resource must not be empty", config)
+	}
+	if config.ReleaseLabel == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ReleaseLabel must not be empty", config)
+	}
+	if (config.OperatorLabel == "") != (config.OperatorName == "") {
+		return nil, microerror.Maskf(invalidConfigError, "%T.OperatorLabel and %T.OperatorName must be set together", config, config)
+	}
+
+	s := &LabelSource{
+		groupResource: schema.GroupResource{
+			Group:    config.Group,
+			Resource: config.Resource,
+		},
+		version:       config.Version,
+		releaseLabel:  config.ReleaseLabel,
+		operatorLabel: config.OperatorLabel,
+		operatorName:  config.OperatorName,
+	}
+
+	return s, nil
+}
+
+func (s *LabelSource) GroupResource() schema.GroupResource {
+	return s.groupResource
+}
+
+func (s *LabelSource) Version() string {
+	return s.version
+}
+
+func (s *LabelSource) ToCluster(m metav1.PartialObjectMetadata) Cluster {
+	c := Cluster{
+		ID:             m.Name,
+		Namespace:      m.Namespace,
+		ReleaseVersion: m.Labels[s.releaseLabel],
+	}
+	if s.operatorLabel != "" {
+		c.OperatorVersion = m.Labels[s.operatorLabel]
+		c.ProviderOperator = s.operatorName
+	}
+
+	return c
+}
+
+// DefaultSourceConfigs are the sources watched when none are configured. CAPI
+// clusters cover all providers based on Cluster API, e.g. CAPA, CAPZ or
+// vSphere.
+var DefaultSourceConfigs = []LabelSourceConfig{
+	{
+		Group:        "cluster.x-k8s.io",
+		Resource:     "clusters",
+		ReleaseLabel: apiexlabels.ReleaseVersion,
+	},
+	{
+		Group:         "infrastructure.giantswarm.io",
+		Resource:      "awsclusters",
+		ReleaseLabel:  apiexlabels.ReleaseVersion,
+		OperatorLabel: apiexlabels.AWSOperatorVersion,
+		OperatorName:  key.ProviderOperatorAWS,
+	},
+	{
+		Group:         "provider.giantswarm.io",
+		Resource:      "kvmconfigs",
+		ReleaseLabel:  apiexlabels.ReleaseVersion,
+		OperatorLabel: apiexlabels.KVMOperatorVersion,
+		OperatorName:  key.ProviderOperatorKVM,
+	},
+}
+
+// NewSources creates a LabelSource for every given configuration.
+func NewSources(configs []LabelSourceConfig) ([]Source, error) {
+	var sources []Source
+	for _, c := range configs {
+		s, err := NewLabelSource(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		sources = append(sources, s)
+	}
+
+	return sources, nil
+}
+
+// watchedResource is a resource watched by the inventory. Only the metadata
+// of the objects is cached.
+type watchedResource struct {
+	Resource      schema.GroupVersionResource
+	LabelSelector string
+	// Source converts cached objects into clusters. It is nil for resources
+	// which do not represent clusters.
+	Source Source
+}
+
+// kvmOperatorPodResource lists the pods kvm-operator still needs to drain.
+var kvmOperatorPodResource = watchedResource{
+	Resource: schema.GroupVersionResource{
+		Version:  "v1",
+		Resource: "pods",
+	},
+	LabelSelector: fmt.Sprintf("%s=%s", PodWatcherLabel, key.ProviderOperatorKVM),
+}
+
+func (w watchedResource) String() string {
+	return w.Resource.String()
+}
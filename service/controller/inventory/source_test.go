@@ -0,0 +1,169 @@
+package inventory
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_NewLabelSource(t *testing.T) {
+	testCases := []struct {
+		name         string
+		config       LabelSourceConfig
+		errorMatcher func(error) bool
+	}{
+		{
+			name: "case 0: release label only",
+			config: LabelSourceConfig{
+				Group:        "cluster.x-k8s.io",
+				Resource:     "clusters",
+				ReleaseLabel: "release.giantswarm.io/version",
+			},
+		},
+		{
+			name: "case 1: release and operator label",
+			config: LabelSourceConfig{
+				Group:         "provider.giantswarm.io",
+				Version:       "v1alpha1",
+				Resource:      "azureconfigs",
+				ReleaseLabel:  "release.giantswarm.io/version",
+				OperatorLabel: "azure-operator.giantswarm.io/version",
+				OperatorName:  "azure-operator",
+			},
+		},
+		{
+			name: "case 2: missing resource",
+			config: LabelSourceConfig{
+				Group:        "cluster.x-k8s.io",
+				ReleaseLabel: "release.giantswarm.io/version",
+			},
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name: "case 3: missing release label",
+			config: LabelSourceConfig{
+				Group:    "cluster.x-k8s.io",
+				Resource: "clusters",
+			},
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name: "case 4: operator label without operator name",
+			config: LabelSourceConfig{
+				Group:         "provider.giantswarm.io",
+				Resource:      "azureconfigs",
+				ReleaseLabel:  "release.giantswarm.io/version",
+				OperatorLabel: "azure-operator.giantswarm.io/version",
+			},
+			errorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			_, err := NewLabelSource(tc.config)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+		})
+	}
+}
+
+func Test_LabelSource_ToCluster(t *testing.T) {
+	testCases := []struct {
+		name            string
+		config          LabelSourceConfig
+		labels          map[string]string
+		expectedCluster Cluster
+	}{
+		{
+			name: "case 0: release label only",
+			config: LabelSourceConfig{
+				Group:        "cluster.x-k8s.io",
+				Resource:     "clusters",
+				ReleaseLabel: "release.giantswarm.io/version",
+			},
+			labels: map[string]string{
+				"aws-operator.giantswarm.io/version": "8.7.0",
+				"release.giantswarm.io/version":      "20.0.0",
+			},
+			expectedCluster: Cluster{
+				ID:             "abc12",
+				Namespace:      "default",
+				ReleaseVersion: "20.0.0",
+			},
+		},
+		{
+			name: "case 1: release and operator label",
+			config: LabelSourceConfig{
+				Group:         "provider.giantswarm.io",
+				Resource:      "azureconfigs",
+				ReleaseLabel:  "release.giantswarm.io/version",
+				OperatorLabel: "azure-operator.giantswarm.io/version",
+				OperatorName:  "azure-operator",
+			},
+			labels: map[string]string{
+				"azure-operator.giantswarm.io/version": "5.0.0",
+				"release.giantswarm.io/version":        "14.1.0",
+			},
+			expectedCluster: Cluster{
+				ID:               "abc12",
+				Namespace:        "default",
+				OperatorVersion:  "5.0.0",
+				ProviderOperator: "azure-operator",
+				ReleaseVersion:   "14.1.0",
+			},
+		},
+		{
+			name: "case 2: missing labels",
+			config: LabelSourceConfig{
+				Group:         "provider.giantswarm.io",
+				Resource:      "azureconfigs",
+				ReleaseLabel:  "release.giantswarm.io/version",
+				OperatorLabel: "azure-operator.giantswarm.io/version",
+				OperatorName:  "azure-operator",
+			},
+			expectedCluster: Cluster{
+				ID:               "abc12",
+				Namespace:        "default",
+				ProviderOperator: "azure-operator",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			s, err := NewLabelSource(tc.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			m := metav1.PartialObjectMetadata{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "abc12",
+					Namespace: "default",
+					Labels:    tc.labels,
+				},
+			}
+
+			cluster := s.ToCluster(m)
+			if !cmp.Equal(cluster, tc.expectedCluster) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedCluster, cluster))
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package inventory
+
+import (
+	"context"
+)
+
+// Interface is implemented by Inventory. It allows consumers to be tested
+// without running informers.
+type Interface interface {
+	// Snapshot returns the clusters currently known to the inventory.
+	Snapshot(ctx context.Context) (Snapshot, error)
+}
@@ -0,0 +1,68 @@
+package key
+
+import (
+	"sort"
+)
+
+// DependencyCycles returns the sorted names of all nodes which are part of a
+// cycle in the given dependency graph. The graph maps every node to the nodes
+// it depends on. Dependencies on nodes missing from the graph are ignored.
+func DependencyCycles(graph map[string][]string) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := map[string]int{}
+	inCycle := map[string]bool{}
+
+	var path []string
+	var visit func(node string)
+	visit = func(node string) {
+		state[node] = visiting
+		path = append(path, node)
+
+		for _, dep := range graph[node] {
+			if _, ok := graph[dep]; !ok {
+				continue
+			}
+
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				// Every node on the path since dep is part of the cycle.
+				for i := len(path) - 1; i >= 0; i-- {
+					inCycle[path[i]] = true
+					if path[i] == dep {
+						break
+					}
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[node] = visited
+	}
+
+	var nodes []string
+	for node := range graph {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	for _, node := range nodes {
+		if state[node] == unvisited {
+			visit(node)
+		}
+	}
+
+	var cycles []string
+	for node := range inCycle {
+		cycles = append(cycles, node)
+	}
+	sort.Strings(cycles)
+
+	return cycles
+}
@@ -0,0 +1,80 @@
+package key
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_DependencyCycles(t *testing.T) {
+	testCases := []struct {
+		name           string
+		graph          map[string][]string
+		expectedCycles []string
+	}{
+		{
+			name: "case 0: no dependencies",
+			graph: map[string][]string{
+				"a": nil,
+				"b": nil,
+			},
+			expectedCycles: nil,
+		},
+		{
+			name: "case 1: chain without cycle",
+			graph: map[string][]string{
+				"a": {"b"},
+				"b": {"c"},
+				"c": nil,
+			},
+			expectedCycles: nil,
+		},
+		{
+			name: "case 2: self dependency",
+			graph: map[string][]string{
+				"a": {"a"},
+				"b": nil,
+			},
+			expectedCycles: []string{"a"},
+		},
+		{
+			name: "case 3: cycle with a dependant outside of it",
+			graph: map[string][]string{
+				"a": {"b"},
+				"b": {"c"},
+				"c": {"a"},
+				"d": {"a"},
+			},
+			expectedCycles: []string{"a", "b", "c"},
+		},
+		{
+			name: "case 4: dependencies on unknown nodes are ignored",
+			graph: map[string][]string{
+				"a": {"unknown"},
+			},
+			expectedCycles: nil,
+		},
+		{
+			name: "case 5: diamond without cycle",
+			graph: map[string][]string{
+				"a": {"b", "c"},
+				"b": {"d"},
+				"c": {"d"},
+				"d": nil,
+			},
+			expectedCycles: nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			cycles := DependencyCycles(tc.graph)
+			if !cmp.Equal(cycles, tc.expectedCycles) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedCycles, cycles))
+			}
+		})
+	}
+}
@@ -2,11 +2,13 @@ package key
 
 import (
 	"fmt"
+	"time"
 
 	applicationv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
 	corev1alpha1 "github.com/giantswarm/config-controller/api/v1alpha1"
 	apiexlabels "github.com/giantswarm/k8smetadata/pkg/label"
 	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
@@ -19,6 +21,27 @@ const (
 	// ReconcileDeprecatedReleaseAnnotation makes a Release to never be skipped, even though is deprecated or not used.
 	ReconcileDeprecatedReleaseAnnotation = "release-operator.giantswarm.io/reconcile-deprecated"
 
+	// AllowSpecChangesAnnotation set to "true" allows changing the spec of a Release which is active and in use beyond its
+	// state, end of life date and notice.
+	AllowSpecChangesAnnotation = "release-operator.giantswarm.io/allow-spec-changes"
+
+	// StateChangedByAnnotation and StateChangedToAnnotation are set by the
+	// mutating webhook to the user who last changed the state of a Release and
+	// the state it was changed to, so that the transition can be recorded in
+	// the status.
+	StateChangedByAnnotation = "release-operator.giantswarm.io/state-changed-by"
+	StateChangedToAnnotation = "release-operator.giantswarm.io/state-changed-to"
+
+	// ProtectReleaseAnnotation prevents an unused deprecated Release from being garbage collected.
+	ProtectReleaseAnnotation = "release-operator.giantswarm.io/protect"
+
+	// DefaultAppOperatorVersion is the app-operator version label of App CRs
+	// when neither the component nor the operator configuration sets one.
+	DefaultAppOperatorVersion = "0.0.0"
+
+	// DefaultAppCatalog is the catalog of release apps not specifying one.
+	DefaultAppCatalog = "default"
+
 	// Namespace is the namespace where App CRs are created.
 	Namespace = "giantswarm"
 
@@ -46,7 +69,7 @@ func AppReferenced(app applicationv1alpha1.App, components map[string]releasev1a
 
 func ConfigReferenced(config corev1alpha1.Config, components map[string]releasev1alpha1.ReleaseSpecComponent) bool {
 	component, ok := components[config.Name]
-	if ok && IsSameConfig(component, config) {
+	if ok && !component.SkipConfig && IsSameConfig(component, config) {
 		return true
 	}
 
@@ -57,32 +80,107 @@ func BuildAppName(component releasev1alpha1.ReleaseSpecComponent) string {
 	return fmt.Sprintf("%s-%s", component.Name, component.Version)
 }
 
+func BuildReleaseAppName(app releasev1alpha1.ReleaseSpecApp) string {
+	return fmt.Sprintf("%s-%s", app.Name, app.Version)
+}
+
 func BuildConfigName(component releasev1alpha1.ReleaseSpecComponent) string {
 	return fmt.Sprintf("%s-%s", component.Name, component.Version)
 }
 
 func ConstructApp(component releasev1alpha1.ReleaseSpecComponent) applicationv1alpha1.App {
+	appOperatorVersion := component.AppOperatorVersion
+	if appOperatorVersion == "" {
+		appOperatorVersion = DefaultAppOperatorVersion
+	}
+	namespace := component.Namespace
+	if namespace == "" {
+		namespace = Namespace
+	}
+
+	labels := map[string]string{}
+	for k, v := range component.ExtraLabels {
+		labels[k] = v
+	}
+	labels[LabelAppOperatorVersion] = appOperatorVersion
+	labels[LabelManagedBy] = project.Name()
+
+	var annotations map[string]string
+	if len(component.ExtraAnnotations) > 0 {
+		annotations = map[string]string{}
+		for k, v := range component.ExtraAnnotations {
+			annotations[k] = v
+		}
+	}
+
+	kubeConfig := applicationv1alpha1.AppSpecKubeConfig{
+		InCluster: true,
+	}
+	if component.KubeConfigSecret != nil {
+		kubeConfig = applicationv1alpha1.AppSpecKubeConfig{
+			Secret: applicationv1alpha1.AppSpecKubeConfigSecret{
+				Name:      component.KubeConfigSecret.Name,
+				Namespace: component.KubeConfigSecret.Namespace,
+			},
+		}
+	}
+
+	var userConfig applicationv1alpha1.AppSpecUserConfig
+	if component.UserConfig != nil && component.UserConfig.ConfigMap != nil {
+		userConfig.ConfigMap = applicationv1alpha1.AppSpecUserConfigConfigMap{
+			Name:      component.UserConfig.ConfigMap.Name,
+			Namespace: component.UserConfig.ConfigMap.Namespace,
+		}
+	}
+	if component.UserConfig != nil && component.UserConfig.Secret != nil {
+		userConfig.Secret = applicationv1alpha1.AppSpecUserConfigSecret{
+			Name:      component.UserConfig.Secret.Name,
+			Namespace: component.UserConfig.Secret.Namespace,
+		}
+	}
+
 	return applicationv1alpha1.App{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      BuildAppName(component),
-			Namespace: Namespace,
-			Labels: map[string]string{
-				LabelAppOperatorVersion: "0.0.0",
-				LabelManagedBy:          project.Name(),
-			},
+			Name:        BuildAppName(component),
+			Namespace:   Namespace,
+			Labels:      labels,
+			Annotations: annotations,
 		},
 		Spec: applicationv1alpha1.AppSpec{
 			Catalog: component.Catalog,
-			KubeConfig: applicationv1alpha1.AppSpecKubeConfig{
-				InCluster: true,
+			Install: applicationv1alpha1.AppSpecInstall{
+				Timeout: component.InstallTimeout,
 			},
-			Name:      component.Name,
-			Namespace: Namespace,
-			Version:   GetComponentRef(component),
+			KubeConfig: kubeConfig,
+			Name:       component.Name,
+			Namespace:  namespace,
+			Upgrade: applicationv1alpha1.AppSpecUpgrade{
+				Timeout: component.UpgradeTimeout,
+			},
+			UserConfig: userConfig,
+			Version:    GetComponentRef(component),
 		},
 	}
 }
 
+// ConstructReleaseApp returns the App CR deploying the given release app on
+// the management cluster. Unlike components, release apps have no Config CR.
+// The given operator wide defaults apply to them like to components.
+func ConstructReleaseApp(app releasev1alpha1.ReleaseSpecApp, defaults ComponentDefaults) applicationv1alpha1.App {
+	catalog := app.Catalog
+	if catalog == "" {
+		catalog = DefaultAppCatalog
+	}
+
+	component := releasev1alpha1.ReleaseSpecComponent{
+		Catalog: catalog,
+		Name:    app.Name,
+		Version: app.Version,
+	}
+
+	return ConstructApp(WithComponentDefaults(component, defaults))
+}
+
 func ConstructConfig(component releasev1alpha1.ReleaseSpecComponent) corev1alpha1.Config {
 	return corev1alpha1.Config{
 		ObjectMeta: metav1.ObjectMeta{
@@ -103,6 +201,34 @@ func ConstructConfig(component releasev1alpha1.ReleaseSpecComponent) corev1alpha
 	}
 }
 
+// ComponentDefaults are operator wide defaults for the App CR related fields
+// of components which are not set in the Release CR.
+type ComponentDefaults struct {
+	AppOperatorVersion string
+	InstallTimeout     time.Duration
+	Namespace          string
+	UpgradeTimeout     time.Duration
+}
+
+// WithComponentDefaults returns the given component with all unset App CR
+// related fields set to the given defaults.
+func WithComponentDefaults(component releasev1alpha1.ReleaseSpecComponent, defaults ComponentDefaults) releasev1alpha1.ReleaseSpecComponent {
+	if component.AppOperatorVersion == "" {
+		component.AppOperatorVersion = defaults.AppOperatorVersion
+	}
+	if component.InstallTimeout == nil && defaults.InstallTimeout != 0 {
+		component.InstallTimeout = &metav1.Duration{Duration: defaults.InstallTimeout}
+	}
+	if component.Namespace == "" {
+		component.Namespace = defaults.Namespace
+	}
+	if component.UpgradeTimeout == nil && defaults.UpgradeTimeout != 0 {
+		component.UpgradeTimeout = &metav1.Duration{Duration: defaults.UpgradeTimeout}
+	}
+
+	return component
+}
+
 func ExcludeDeletedRelease(releases releasev1alpha1.ReleaseList) releasev1alpha1.ReleaseList {
 	var active releasev1alpha1.ReleaseList
 	for _, release := range releases.Items {
@@ -117,8 +243,9 @@ func ExcludeUnusedDeprecatedReleases(releases releasev1alpha1.ReleaseList) relea
 	var active releasev1alpha1.ReleaseList
 
 	for _, release := range releases.Items {
+		deprecated := release.Spec.State == releasev1alpha1.StateDeprecated || release.Status.State == releasev1alpha1.StateDeprecated
 
-		if release.Spec.State == releasev1alpha1.StateDeprecated && !release.Status.InUse && release.Annotations[ReconcileDeprecatedReleaseAnnotation] == "" {
+		if deprecated && !release.Status.InUse && release.Annotations[ReconcileDeprecatedReleaseAnnotation] == "" {
 			// skip
 		} else {
 			active.Items = append(active.Items, release)
@@ -128,13 +255,118 @@ func ExcludeUnusedDeprecatedReleases(releases releasev1alpha1.ReleaseList) relea
 	return active
 }
 
+// ReadySince returns since when all components of the given release are
+// deployed according to its status. It returns false when the release is not
+// ready.
+func ReadySince(release releasev1alpha1.Release) (time.Time, bool) {
+	if !release.Status.Ready {
+		return time.Time{}, false
+	}
+
+	condition := meta.FindStatusCondition(release.Status.Conditions, releasev1alpha1.ConditionComponentsDeployed)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		return time.Time{}, false
+	}
+
+	return condition.LastTransitionTime.Time, true
+}
+
+// StatePromotion configures how releases are promoted to the active state.
+type StatePromotion struct {
+	// Enforce requires releases to be created as wip or preview and to be
+	// ready for SoakTime before they become active.
+	Enforce  bool
+	SoakTime time.Duration
+}
+
+// StateTransitionError returns why the state of the given release must not
+// change from the given state to the given state, or an empty string if the
+// transition is allowed. An empty from state means the release is created.
+// Deprecated releases must never move back to wip.
+func StateTransitionError(release releasev1alpha1.Release, from, to releasev1alpha1.ReleaseState, promotion StatePromotion, now time.Time) string {
+	if from == to {
+		return ""
+	}
+
+	if from == releasev1alpha1.StateDeprecated && to == releasev1alpha1.StateWIP {
+		return fmt.Sprintf("must not be changed from %#q to %#q", from, to)
+	}
+
+	if !promotion.Enforce || to != releasev1alpha1.StateActive {
+		return ""
+	}
+
+	if from == "" {
+		return fmt.Sprintf("must be %#q or %#q on creation, releases become active once they are ready", releasev1alpha1.StateWIP, releasev1alpha1.StatePreview)
+	}
+
+	readySince, ready := ReadySince(release)
+	if !ready {
+		return "must not be active before all components are deployed"
+	}
+	if now.Sub(readySince) < promotion.SoakTime {
+		return fmt.Sprintf("must not be active before all components are deployed for %s, they are deployed since %s", promotion.SoakTime, readySince.UTC().Format(time.RFC3339))
+	}
+
+	return ""
+}
+
+// RetentionExpired returns whether the given release may be garbage
+// collected. This is the case when it is deprecated, not protected and has
+// been unused for at least the given retention period according to its
+// status.
+func RetentionExpired(release releasev1alpha1.Release, now time.Time, retention time.Duration) bool {
+	deprecated := release.Spec.State == releasev1alpha1.StateDeprecated || release.Status.State == releasev1alpha1.StateDeprecated
+
+	if !deprecated || release.Status.InUse || release.DeletionTimestamp != nil {
+		return false
+	}
+	if release.Annotations[ProtectReleaseAnnotation] != "" {
+		return false
+	}
+	// The status must reflect the current spec, otherwise it may not have
+	// been checked whether the release is in use yet.
+	if release.Status.LastUsedTime == nil || release.Status.ObservedGeneration != release.Generation {
+		return false
+	}
+
+	return now.Sub(release.Status.LastUsedTime.Time) >= retention
+}
+
+// EndOfLifeReached returns whether the end of life date of the given release
+// passed.
+func EndOfLifeReached(release releasev1alpha1.Release, now time.Time) bool {
+	return release.Spec.EndOfLifeDate != nil && !now.Before(release.Spec.EndOfLifeDate.Time)
+}
+
+// EffectiveState returns the state of the given release, which is deprecated
+// once its end of life date passed regardless of its spec.
+func EffectiveState(release releasev1alpha1.Release, now time.Time) releasev1alpha1.ReleaseState {
+	if EndOfLifeReached(release, now) {
+		return releasev1alpha1.StateDeprecated
+	}
+
+	return release.Spec.State
+}
+
+// UntilEndOfLife returns the duration until the end of life date of the given
+// release. It is zero when the release has no end of life date or it already
+// passed.
+func UntilEndOfLife(release releasev1alpha1.Release, now time.Time) time.Duration {
+	if release.Spec.EndOfLifeDate == nil || EndOfLifeReached(release, now) {
+		return 0
+	}
+
+	return release.Spec.EndOfLifeDate.Sub(now)
+}
+
 // ExtractComponents extracts the components that this operator is responsible for.
 func ExtractComponents(releases releasev1alpha1.ReleaseList) map[string]releasev1alpha1.ReleaseSpecComponent {
 	var components = make(map[string]releasev1alpha1.ReleaseSpecComponent)
 
 	for _, release := range releases.Items {
 		for _, component := range release.Spec.Components {
-			if component.ReleaseOperatorDeploy && (components[BuildAppName(component)] == releasev1alpha1.ReleaseSpecComponent{}) {
+			if _, ok := components[BuildAppName(component)]; component.ReleaseOperatorDeploy && !ok {
 				components[BuildAppName(component)] = component
 			}
 		}
@@ -142,6 +374,132 @@ func ExtractComponents(releases releasev1alpha1.ReleaseList) map[string]releasev
 	return components
 }
 
+// ExtractApps extracts the release apps that this operator is responsible for,
+// keyed by their App name.
+func ExtractApps(releases releasev1alpha1.ReleaseList) map[string]releasev1alpha1.ReleaseSpecApp {
+	var apps = make(map[string]releasev1alpha1.ReleaseSpecApp)
+
+	for _, release := range releases.Items {
+		for _, app := range FilterApps(release.Spec.Apps) {
+			if _, ok := apps[BuildReleaseAppName(app)]; !ok {
+				apps[BuildReleaseAppName(app)] = app
+			}
+		}
+	}
+	return apps
+}
+
+// ExtractAppDependencies returns the names of the App CRs every release app
+// deployed by this operator depends on, keyed by its App name. Dependencies
+// are resolved by app name within the release declaring them. When the same
+// app is part of several releases, the dependencies of all of them are
+// combined.
+func ExtractAppDependencies(releases releasev1alpha1.ReleaseList) map[string][]string {
+	var dependencies = make(map[string][]string)
+
+	for _, release := range releases.Items {
+		mergeDependencies(dependencies, AppDependencies(release.Spec.Apps))
+	}
+	return dependencies
+}
+
+// ExtractComponentDependencies returns the names of the App CRs every
+// component deployed by this operator depends on, keyed by its App name.
+// Dependencies are resolved like in ExtractAppDependencies.
+func ExtractComponentDependencies(releases releasev1alpha1.ReleaseList) map[string][]string {
+	var dependencies = make(map[string][]string)
+
+	for _, release := range releases.Items {
+		mergeDependencies(dependencies, ComponentDependencies(release.Spec.Components))
+	}
+	return dependencies
+}
+
+// AppDependencies returns the names of the App CRs every release app deployed
+// by this operator depends on, keyed by its App name. Dependencies on apps not
+// deployed by this operator are left out since they have no App CR to wait
+// for.
+func AppDependencies(apps []releasev1alpha1.ReleaseSpecApp) map[string][]string {
+	appNames := map[string]string{}
+	for _, app := range FilterApps(apps) {
+		appNames[app.Name] = BuildReleaseAppName(app)
+	}
+
+	dependencies := map[string][]string{}
+	for _, app := range FilterApps(apps) {
+		deps := []string{}
+		for _, name := range app.DependsOn {
+			dep, ok := appNames[name]
+			if ok {
+				deps = append(deps, dep)
+			}
+		}
+		dependencies[BuildReleaseAppName(app)] = deps
+	}
+	return dependencies
+}
+
+// ComponentDependencies returns the names of the App CRs every component
+// deployed by this operator depends on, keyed by its App name. Dependencies on
+// components not deployed by this operator are left out since they have no
+// App CR to wait for.
+func ComponentDependencies(components []releasev1alpha1.ReleaseSpecComponent) map[string][]string {
+	appNames := map[string]string{}
+	for _, component := range FilterComponents(components) {
+		appNames[component.Name] = BuildAppName(component)
+	}
+
+	dependencies := map[string][]string{}
+	for _, component := range FilterComponents(components) {
+		deps := []string{}
+		for _, name := range component.DependsOn {
+			dep, ok := appNames[name]
+			if ok {
+				deps = append(deps, dep)
+			}
+		}
+		dependencies[BuildAppName(component)] = deps
+	}
+	return dependencies
+}
+
+// FilterApps filters the release apps that this operator is responsible for.
+func FilterApps(apps []releasev1alpha1.ReleaseSpecApp) []releasev1alpha1.ReleaseSpecApp {
+	var filteredApps []releasev1alpha1.ReleaseSpecApp
+	for _, a := range apps {
+		if a.ReleaseOperatorDeploy {
+			filteredApps = append(filteredApps, a)
+		}
+	}
+	return filteredApps
+}
+
+// ReleasesDeployingApp returns the releases having a component or release app
+// deployed by release-operator as the App with the given name.
+func ReleasesDeployingApp(releases []releasev1alpha1.Release, appName string) []releasev1alpha1.Release {
+	var deploying []releasev1alpha1.Release
+
+	for _, release := range releases {
+		var found bool
+		for _, component := range FilterComponents(release.Spec.Components) {
+			if BuildAppName(component) == appName {
+				found = true
+			}
+		}
+		for _, app := range FilterApps(release.Spec.Apps) {
+			if BuildReleaseAppName(app) == appName {
+				found = true
+			}
+		}
+
+		if found {
+			deploying = append(deploying, release)
+		}
+	}
+
+	return deploying
+}
+
 // FilterComponents filters the components that this operator is responsible for.
 func FilterComponents(comps []releasev1alpha1.ReleaseSpecComponent) []releasev1alpha1.ReleaseSpecComponent {
 	var filteredComponents []releasev1alpha1.ReleaseSpecComponent
@@ -195,6 +553,19 @@ func IsSameApp(component releasev1alpha1.ReleaseSpecComponent, app applicationv1
 		GetComponentRef(component) == app.Spec.Version
 }
 
+// IsSameReleaseApp returns whether the given App deploys the given release
+// app.
+func IsSameReleaseApp(releaseApp releasev1alpha1.ReleaseSpecApp, app applicationv1alpha1.App) bool {
+	catalog := releaseApp.Catalog
+	if catalog == "" {
+		catalog = DefaultAppCatalog
+	}
+
+	return BuildReleaseAppName(releaseApp) == app.Name &&
+		catalog == app.Spec.Catalog &&
+		releaseApp.Version == app.Spec.Version
+}
+
 func IsSameConfig(component releasev1alpha1.ReleaseSpecComponent, config corev1alpha1.Config) bool {
 	configManagedByLabel, configIsManagedByReleaseOperator := config.Labels[LabelManagedBy]
 	return component.Name == config.Spec.App.Name &&
@@ -234,6 +605,48 @@ func ComponentConfigCreated(component releasev1alpha1.ReleaseSpecComponent, conf
 	return false
 }
 
+// UndeployedDependencies returns the given App names which do not exist or do
+// not report the deployed status yet.
+func UndeployedDependencies(dependencies []string, apps []applicationv1alpha1.App) []string {
+	var undeployed []string
+	for _, name := range dependencies {
+		deployed := false
+		for _, app := range apps {
+			if app.Name == name && app.Status.Release.Status == AppStatusDeployed {
+				deployed = true
+				break
+			}
+		}
+		if !deployed {
+			undeployed = append(undeployed, name)
+		}
+	}
+
+	return undeployed
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func mergeDependencies(into, dependencies map[string][]string) {
+	for name, deps := range dependencies {
+		merged := into[name]
+		for _, dep := range deps {
+			if !containsString(merged, dep) {
+				merged = append(merged, dep)
+			}
+		}
+		into[name] = merged
+	}
+}
+
 // ToReleaseCR converts v into a Release CR.
 func ToReleaseCR(v interface{}) (*releasev1alpha1.Release, error) {
 	x, ok := v.(*releasev1alpha1.Release)
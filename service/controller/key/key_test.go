@@ -3,6 +3,7 @@ package key
 import (
 	"strconv"
 	"testing"
+	"time"
 
 	applicationv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
 	corev1alpha1 "github.com/giantswarm/config-controller/api/v1alpha1"
@@ -205,6 +206,104 @@ func Test_ConstructApp(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "case 3: passes the component's overrides to the app",
+			component: releasev1alpha1.ReleaseSpecComponent{
+				AppOperatorVersion: "6.0.0",
+				ExtraAnnotations: map[string]string{
+					"example.com/owner": "team",
+				},
+				ExtraLabels: map[string]string{
+					"example.com/tier": "core",
+					LabelManagedBy:     "someone-else",
+				},
+				InstallTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+				KubeConfigSecret: &releasev1alpha1.ReleaseSpecObjectReference{
+					Name:      "kubeconfig",
+					Namespace: "default",
+				},
+				Name:           "test-operator",
+				Namespace:      "operators",
+				UpgradeTimeout: &metav1.Duration{Duration: 15 * time.Minute},
+				Version:        "1.0.0",
+			},
+			expectedApp: applicationv1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-operator-1.0.0",
+					Namespace: Namespace,
+					Labels: map[string]string{
+						"example.com/tier":      "core",
+						LabelAppOperatorVersion: "6.0.0",
+						LabelManagedBy:          project.Name(),
+					},
+					Annotations: map[string]string{
+						"example.com/owner": "team",
+					},
+				},
+				Spec: applicationv1alpha1.AppSpec{
+					Install: applicationv1alpha1.AppSpecInstall{
+						Timeout: &metav1.Duration{Duration: 10 * time.Minute},
+					},
+					KubeConfig: applicationv1alpha1.AppSpecKubeConfig{
+						Secret: applicationv1alpha1.AppSpecKubeConfigSecret{
+							Name:      "kubeconfig",
+							Namespace: "default",
+						},
+					},
+					Name:      "test-operator",
+					Namespace: "operators",
+					Upgrade: applicationv1alpha1.AppSpecUpgrade{
+						Timeout: &metav1.Duration{Duration: 15 * time.Minute},
+					},
+					Version: "1.0.0",
+				},
+			},
+		},
+		{
+			name: "case 4: passes the component's user config to the app",
+			component: releasev1alpha1.ReleaseSpecComponent{
+				Name: "test-operator",
+				UserConfig: &releasev1alpha1.ReleaseSpecComponentUserConfig{
+					ConfigMap: &releasev1alpha1.ReleaseSpecObjectReference{
+						Name:      "test-operator-user-values",
+						Namespace: "giantswarm",
+					},
+					Secret: &releasev1alpha1.ReleaseSpecObjectReference{
+						Name:      "test-operator-user-secrets",
+						Namespace: "giantswarm",
+					},
+				},
+				Version: "1.0.0",
+			},
+			expectedApp: applicationv1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-operator-1.0.0",
+					Namespace: Namespace,
+					Labels: map[string]string{
+						LabelAppOperatorVersion: "0.0.0",
+						LabelManagedBy:          project.Name(),
+					},
+				},
+				Spec: applicationv1alpha1.AppSpec{
+					KubeConfig: applicationv1alpha1.AppSpecKubeConfig{
+						InCluster: true,
+					},
+					Name:      "test-operator",
+					Namespace: Namespace,
+					UserConfig: applicationv1alpha1.AppSpecUserConfig{
+						ConfigMap: applicationv1alpha1.AppSpecUserConfigConfigMap{
+							Name:      "test-operator-user-values",
+							Namespace: "giantswarm",
+						},
+						Secret: applicationv1alpha1.AppSpecUserConfigSecret{
+							Name:      "test-operator-user-secrets",
+							Namespace: "giantswarm",
+						},
+					},
+					Version: "1.0.0",
+				},
+			},
+		},
 	}
 
 	for i, tc := range testCases {
@@ -220,6 +319,155 @@ func Test_ConstructApp(t *testing.T) {
 	}
 }
 
+func Test_WithComponentDefaults(t *testing.T) {
+	defaults := ComponentDefaults{
+		AppOperatorVersion: "5.0.0",
+		InstallTimeout:     10 * time.Minute,
+		Namespace:          "operators",
+	}
+
+	testCases := []struct {
+		name              string
+		component         releasev1alpha1.ReleaseSpecComponent
+		expectedComponent releasev1alpha1.ReleaseSpecComponent
+	}{
+		{
+			name: "case 0: unset fields are defaulted",
+			component: releasev1alpha1.ReleaseSpecComponent{
+				Name:    "test-operator",
+				Version: "1.0.0",
+			},
+			expectedComponent: releasev1alpha1.ReleaseSpecComponent{
+				AppOperatorVersion: "5.0.0",
+				InstallTimeout:     &metav1.Duration{Duration: 10 * time.Minute},
+				Name:               "test-operator",
+				Namespace:          "operators",
+				Version:            "1.0.0",
+			},
+		},
+		{
+			name: "case 1: fields set by the component are kept",
+			component: releasev1alpha1.ReleaseSpecComponent{
+				AppOperatorVersion: "6.0.0",
+				InstallTimeout:     &metav1.Duration{Duration: time.Minute},
+				Name:               "test-operator",
+				Namespace:          "monitoring",
+				Version:            "1.0.0",
+			},
+			expectedComponent: releasev1alpha1.ReleaseSpecComponent{
+				AppOperatorVersion: "6.0.0",
+				InstallTimeout:     &metav1.Duration{Duration: time.Minute},
+				Name:               "test-operator",
+				Namespace:          "monitoring",
+				Version:            "1.0.0",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			result := WithComponentDefaults(tc.component, defaults)
+
+			if !cmp.Equal(result, tc.expectedComponent) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedComponent, result))
+			}
+		})
+	}
+}
+
+func Test_ConstructReleaseApp(t *testing.T) {
+	testCases := []struct {
+		name        string
+		releaseApp  releasev1alpha1.ReleaseSpecApp
+		defaults    ComponentDefaults
+		expectedApp applicationv1alpha1.App
+	}{
+		{
+			name: "case 0: release app without defaults",
+			releaseApp: releasev1alpha1.ReleaseSpecApp{
+				Name:    "test-app",
+				Version: "1.0.0",
+			},
+			expectedApp: applicationv1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-app-1.0.0",
+					Namespace: Namespace,
+					Labels: map[string]string{
+						LabelAppOperatorVersion: DefaultAppOperatorVersion,
+						LabelManagedBy:          project.Name(),
+					},
+				},
+				Spec: applicationv1alpha1.AppSpec{
+					Catalog: DefaultAppCatalog,
+					KubeConfig: applicationv1alpha1.AppSpecKubeConfig{
+						InCluster: true,
+					},
+					Name:      "test-app",
+					Namespace: Namespace,
+					Version:   "1.0.0",
+				},
+			},
+		},
+		{
+			name: "case 1: operator wide defaults apply to release apps",
+			releaseApp: releasev1alpha1.ReleaseSpecApp{
+				Catalog: "control-plane-catalog",
+				Name:    "test-app",
+				Version: "1.0.0",
+			},
+			defaults: ComponentDefaults{
+				AppOperatorVersion: "5.0.0",
+				InstallTimeout:     10 * time.Minute,
+				Namespace:          "operators",
+				UpgradeTimeout:     5 * time.Minute,
+			},
+			expectedApp: applicationv1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-app-1.0.0",
+					Namespace: Namespace,
+					Labels: map[string]string{
+						LabelAppOperatorVersion: "5.0.0",
+						LabelManagedBy:          project.Name(),
+					},
+				},
+				Spec: applicationv1alpha1.AppSpec{
+					Catalog: "control-plane-catalog",
+					Install: applicationv1alpha1.AppSpecInstall{
+						Timeout: &metav1.Duration{Duration: 10 * time.Minute},
+					},
+					KubeConfig: applicationv1alpha1.AppSpecKubeConfig{
+						InCluster: true,
+					},
+					Name:      "test-app",
+					Namespace: "operators",
+					Upgrade: applicationv1alpha1.AppSpecUpgrade{
+						Timeout: &metav1.Duration{Duration: 5 * time.Minute},
+					},
+					Version: "1.0.0",
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			result := ConstructReleaseApp(tc.releaseApp, tc.defaults)
+
+			if !cmp.Equal(result, tc.expectedApp) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedApp, result))
+			}
+
+			if !IsSameReleaseApp(tc.releaseApp, result) {
+				t.Fatalf("expected app %#q to deploy release app %#q", result.Name, tc.releaseApp.Name)
+			}
+		})
+	}
+}
+
 func Test_ConstructConfig(t *testing.T) {
 	testCases := []struct {
 		name           string
@@ -552,6 +800,39 @@ func Test_ExcludeDeprecatedUnusedRelease(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "case 5: an unused release deprecated by its end of life date is deleted",
+			releases: releasev1alpha1.ReleaseList{
+				Items: []releasev1alpha1.Release{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "end-of-life-release",
+						},
+						Spec: releasev1alpha1.ReleaseSpec{
+							State: releasev1alpha1.StateActive,
+						},
+						Status: releasev1alpha1.ReleaseStatus{
+							InUse: false,
+							State: releasev1alpha1.StateDeprecated,
+						},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "not-being-deleted",
+						},
+					},
+				},
+			},
+			expectedReleases: releasev1alpha1.ReleaseList{
+				Items: []releasev1alpha1.Release{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "not-being-deleted",
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for i, tc := range testCases {
@@ -567,6 +848,255 @@ func Test_ExcludeDeprecatedUnusedRelease(t *testing.T) {
 	}
 }
 
+func Test_EffectiveState(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name                   string
+		release                releasev1alpha1.Release
+		expectedState          releasev1alpha1.ReleaseState
+		expectedUntilEndOfLife time.Duration
+	}{
+		{
+			name: "case 0: release without end of life date keeps its state",
+			release: releasev1alpha1.Release{
+				Spec: releasev1alpha1.ReleaseSpec{
+					State: releasev1alpha1.StateActive,
+				},
+			},
+			expectedState:          releasev1alpha1.StateActive,
+			expectedUntilEndOfLife: 0,
+		},
+		{
+			name: "case 1: release before its end of life date keeps its state",
+			release: releasev1alpha1.Release{
+				Spec: releasev1alpha1.ReleaseSpec{
+					EndOfLifeDate: &metav1.Time{Time: now.Add(time.Hour)},
+					State:         releasev1alpha1.StateActive,
+				},
+			},
+			expectedState:          releasev1alpha1.StateActive,
+			expectedUntilEndOfLife: time.Hour,
+		},
+		{
+			name: "case 2: release at its end of life date is deprecated",
+			release: releasev1alpha1.Release{
+				Spec: releasev1alpha1.ReleaseSpec{
+					EndOfLifeDate: &metav1.Time{Time: now},
+					State:         releasev1alpha1.StateActive,
+				},
+			},
+			expectedState:          releasev1alpha1.StateDeprecated,
+			expectedUntilEndOfLife: 0,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			state := EffectiveState(tc.release, now)
+			if !cmp.Equal(state, tc.expectedState) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedState, state))
+			}
+
+			until := UntilEndOfLife(tc.release, now)
+			if !cmp.Equal(until, tc.expectedUntilEndOfLife) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedUntilEndOfLife, until))
+			}
+		})
+	}
+}
+
+func Test_ReadySince(t *testing.T) {
+	deployedAt := metav1.NewTime(time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC))
+
+	testCases := []struct {
+		name          string
+		status        releasev1alpha1.ReleaseStatus
+		expectedSince time.Time
+		expectedReady bool
+	}{
+		{
+			name: "case 0: ready release is ready since its components are deployed",
+			status: releasev1alpha1.ReleaseStatus{
+				Ready: true,
+				Conditions: []metav1.Condition{
+					{
+						Type:               releasev1alpha1.ConditionComponentsDeployed,
+						Status:             metav1.ConditionTrue,
+						LastTransitionTime: deployedAt,
+					},
+				},
+			},
+			expectedSince: deployedAt.Time,
+			expectedReady: true,
+		},
+		{
+			name: "case 1: release which is not ready",
+			status: releasev1alpha1.ReleaseStatus{
+				Ready: false,
+				Conditions: []metav1.Condition{
+					{
+						Type:               releasev1alpha1.ConditionComponentsDeployed,
+						Status:             metav1.ConditionFalse,
+						LastTransitionTime: deployedAt,
+					},
+				},
+			},
+			expectedReady: false,
+		},
+		{
+			name: "case 2: ready release without conditions",
+			status: releasev1alpha1.ReleaseStatus{
+				Ready: true,
+			},
+			expectedReady: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			since, ready := ReadySince(releasev1alpha1.Release{Status: tc.status})
+			if !cmp.Equal(ready, tc.expectedReady) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedReady, ready))
+			}
+			if !cmp.Equal(since, tc.expectedSince) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedSince, since))
+			}
+		})
+	}
+}
+
+func Test_RetentionExpired(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	retention := 24 * time.Hour
+
+	testCases := []struct {
+		name            string
+		release         releasev1alpha1.Release
+		expectedExpired bool
+	}{
+		{
+			name: "case 0: deprecated release unused for longer than the retention expired",
+			release: releasev1alpha1.Release{
+				Spec: releasev1alpha1.ReleaseSpec{
+					State: releasev1alpha1.StateDeprecated,
+				},
+				Status: releasev1alpha1.ReleaseStatus{
+					LastUsedTime: &metav1.Time{Time: now.Add(-2 * retention)},
+				},
+			},
+			expectedExpired: true,
+		},
+		{
+			name: "case 1: release deprecated by its status unused for longer than the retention expired",
+			release: releasev1alpha1.Release{
+				Spec: releasev1alpha1.ReleaseSpec{
+					State: releasev1alpha1.StateActive,
+				},
+				Status: releasev1alpha1.ReleaseStatus{
+					LastUsedTime: &metav1.Time{Time: now.Add(-2 * retention)},
+					State:        releasev1alpha1.StateDeprecated,
+				},
+			},
+			expectedExpired: true,
+		},
+		{
+			name: "case 2: deprecated release unused for less than the retention did not expire",
+			release: releasev1alpha1.Release{
+				Spec: releasev1alpha1.ReleaseSpec{
+					State: releasev1alpha1.StateDeprecated,
+				},
+				Status: releasev1alpha1.ReleaseStatus{
+					LastUsedTime: &metav1.Time{Time: now.Add(-time.Hour)},
+				},
+			},
+			expectedExpired: false,
+		},
+		{
+			name: "case 3: deprecated release in use did not expire",
+			release: releasev1alpha1.Release{
+				Spec: releasev1alpha1.ReleaseSpec{
+					State: releasev1alpha1.StateDeprecated,
+				},
+				Status: releasev1alpha1.ReleaseStatus{
+					InUse:        true,
+					LastUsedTime: &metav1.Time{Time: now.Add(-2 * retention)},
+				},
+			},
+			expectedExpired: false,
+		},
+		{
+			name: "case 4: active release did not expire",
+			release: releasev1alpha1.Release{
+				Spec: releasev1alpha1.ReleaseSpec{
+					State: releasev1alpha1.StateActive,
+				},
+				Status: releasev1alpha1.ReleaseStatus{
+					LastUsedTime: &metav1.Time{Time: now.Add(-2 * retention)},
+				},
+			},
+			expectedExpired: false,
+		},
+		{
+			name: "case 5: protected release did not expire",
+			release: releasev1alpha1.Release{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ProtectReleaseAnnotation: "true",
+					},
+				},
+				Spec: releasev1alpha1.ReleaseSpec{
+					State: releasev1alpha1.StateDeprecated,
+				},
+				Status: releasev1alpha1.ReleaseStatus{
+					LastUsedTime: &metav1.Time{Time: now.Add(-2 * retention)},
+				},
+			},
+			expectedExpired: false,
+		},
+		{
+			name: "case 6: release without last used time did not expire",
+			release: releasev1alpha1.Release{
+				Spec: releasev1alpha1.ReleaseSpec{
+					State: releasev1alpha1.StateDeprecated,
+				},
+			},
+			expectedExpired: false,
+		},
+		{
+			name: "case 7: release with outdated status did not expire",
+			release: releasev1alpha1.Release{
+				ObjectMeta: metav1.ObjectMeta{
+					Generation: 2,
+				},
+				Spec: releasev1alpha1.ReleaseSpec{
+					State: releasev1alpha1.StateDeprecated,
+				},
+				Status: releasev1alpha1.ReleaseStatus{
+					LastUsedTime:       &metav1.Time{Time: now.Add(-2 * retention)},
+					ObservedGeneration: 1,
+				},
+			},
+			expectedExpired: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			expired := RetentionExpired(tc.release, now, retention)
+			if !cmp.Equal(expired, tc.expectedExpired) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedExpired, expired))
+			}
+		})
+	}
+}
+
 func Test_ExtractComponents(t *testing.T) {
 	testCases := []struct {
 		name               string
@@ -693,6 +1223,116 @@ func Test_FilterComponents(t *testing.T) {
 	}
 }
 
+func Test_AppDependencies(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		apps                 []releasev1alpha1.ReleaseSpecApp
+		expectedDependencies map[string][]string
+	}{
+		{
+			name: "case 0: dependency on a deployed app is resolved to its App name",
+			apps: []releasev1alpha1.ReleaseSpecApp{
+				{Name: "a", Version: "1.0.0", ReleaseOperatorDeploy: true, DependsOn: []string{"b"}},
+				{Name: "b", Version: "1.0.0", ReleaseOperatorDeploy: true},
+			},
+			expectedDependencies: map[string][]string{
+				"a-1.0.0": {"b-1.0.0"},
+				"b-1.0.0": {},
+			},
+		},
+		{
+			name: "case 1: dependency on an app not deployed by release-operator is left out",
+			apps: []releasev1alpha1.ReleaseSpecApp{
+				{Name: "a", Version: "1.0.0", ReleaseOperatorDeploy: true, DependsOn: []string{"b"}},
+				{Name: "b", Version: "1.0.0", ReleaseOperatorDeploy: false},
+			},
+			expectedDependencies: map[string][]string{
+				"a-1.0.0": {},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			result := AppDependencies(tc.apps)
+
+			if !cmp.Equal(result, tc.expectedDependencies) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedDependencies, result))
+			}
+		})
+	}
+}
+
+func Test_ReleasesDeployingApp(t *testing.T) {
+	releases := []releasev1alpha1.Release{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "v1.0.0",
+			},
+			Spec: releasev1alpha1.ReleaseSpec{
+				Components: []releasev1alpha1.ReleaseSpecComponent{
+					{Name: "app-operator", ReleaseOperatorDeploy: true, Version: "1.0.0"},
+				},
+				Apps: []releasev1alpha1.ReleaseSpecApp{
+					{Name: "bundle", ReleaseOperatorDeploy: true, Version: "2.0.0"},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "v1.1.0",
+			},
+			Spec: releasev1alpha1.ReleaseSpec{
+				Components: []releasev1alpha1.ReleaseSpecComponent{
+					{Name: "app-operator", ReleaseOperatorDeploy: true, Version: "1.0.0"},
+				},
+				Apps: []releasev1alpha1.ReleaseSpecApp{
+					{Name: "bundle", Version: "2.0.0"},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name             string
+		appName          string
+		expectedReleases []string
+	}{
+		{
+			name:             "case 0: component app deployed by multiple releases",
+			appName:          "app-operator-1.0.0",
+			expectedReleases: []string{"v1.0.0", "v1.1.0"},
+		},
+		{
+			name:             "case 1: release app only deployed by the release setting releaseOperatorDeploy",
+			appName:          "bundle-2.0.0",
+			expectedReleases: []string{"v1.0.0"},
+		},
+		{
+			name:             "case 2: app not deployed by any release",
+			appName:          "app-operator-0.1.0",
+			expectedReleases: nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			var names []string
+			for _, release := range ReleasesDeployingApp(releases, tc.appName) {
+				names = append(names, release.Name)
+			}
+
+			if !cmp.Equal(names, tc.expectedReleases) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedReleases, names))
+			}
+		})
+	}
+}
+
 func Test_IsSameApp(t *testing.T) {
 	testCases := []struct {
 		name           string
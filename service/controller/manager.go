@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/backoff"
+	"github.com/giantswarm/k8sclient/v7/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/operatorkit/v7/pkg/controller"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+// ManagedController is a controller run by the Manager.
+type ManagedController interface {
+	// SetupWithManager registers the controller and its watches with the
+	// given manager. It is called again for every boot attempt.
+	SetupWithManager(mgr manager.Manager) error
+}
+
+type ManagerConfig struct {
+	K8sClient k8sclient.Interface
+	Logger    micrologger.Logger
+
+	// Controllers are set up with the manager in the given order.
+	Controllers []ManagedController
+}
+
+// Manager runs all controllers of release-operator on a single
+// controller-runtime manager, so that Releases, Apps and Configs are cached
+// only once. It replaces the boot of the operatorkit controller, which only
+// watches the primary objects, and keeps its boot behaviour: failed boots are
+// retried with a backoff and Booted is closed once the controllers are set
+// up.
+type Manager struct {
+	k8sClient   k8sclient.Interface
+	logger      micrologger.Logger
+	controllers []ManagedController
+
+	booted   chan struct{}
+	bootOnce sync.Once
+}
+
+func NewManager(config ManagerConfig) (*Manager, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	m := &Manager{
+		k8sClient:   config.K8sClient,
+		logger:      config.Logger,
+		controllers: config.Controllers,
+
+		booted: make(chan struct{}),
+	}
+
+	return m, nil
+}
+
+// Boot starts the manager and blocks until the context is cancelled. Failed
+// boots are retried with a backoff before an error is returned.
+func (m *Manager) Boot(ctx context.Context) error {
+	operation := func() error {
+		err := m.bootWithError(ctx)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	}
+
+	notifier := backoff.NewNotifier(m.logger, ctx)
+
+	err := backoff.RetryNotify(operation, backoff.NewMaxRetries(7, 1*time.Second), notifier)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// Booted is closed once all controllers are set up with the manager.
+func (m *Manager) Booted() chan struct{} {
+	return m.booted
+}
+
+func (m *Manager) bootWithError(ctx context.Context) error {
+	var mgr manager.Manager
+	{
+		o := manager.Options{
+			Cache: cache.Options{
+				SyncPeriod: ptr.To(controller.DefaultResyncPeriod),
+			},
+			Controller: config.Controller{
+				// Controllers are registered again on every boot attempt.
+				SkipNameValidation: ptr.To(true),
+			},
+			Metrics: server.Options{
+				// Metrics are served by the operator itself.
+				BindAddress: controller.DisableMetricsServing,
+			},
+			Scheme: m.k8sClient.Scheme(),
+		}
+
+		var err error
+		mgr, err = manager.New(m.k8sClient.RESTConfig(), o)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	for _, c := range m.controllers {
+		err := c.SetupWithManager(mgr)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	m.bootOnce.Do(func() {
+		close(m.booted)
+	})
+
+	err := mgr.Start(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// resetReconcileErrors periodically resets the reconciliation error metric
+// of the operatorkit controller with the given name, as the operatorkit boot
+// does, so that it reflects recent errors only.
+func resetReconcileErrors(name string, period time.Duration) manager.RunnableFunc {
+	return func(ctx context.Context) error {
+		reconcileErrors := operatorkitReconcileErrors()
+		if reconcileErrors == nil {
+			return nil
+		}
+
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				reconcileErrors.WithLabelValues(name).Set(0)
+			}
+		}
+	}
+}
+
+// operatorkitReconcileErrors returns the reconciliation error metric
+// registered by operatorkit, which it does not export.
+func operatorkitReconcileErrors() *prometheus.GaugeVec {
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: controller.PrometheusNamespace,
+		Subsystem: controller.PrometheusSubsystem,
+		Name:      "errors_total",
+		Help:      "Total number of reconciliation errors per controller",
+	}, []string{"controller"})
+
+	err := prometheus.Register(gauge)
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if errors.As(err, &alreadyRegistered) {
+		existing, ok := alreadyRegistered.ExistingCollector.(*prometheus.GaugeVec)
+		if ok {
+			return existing
+		}
+		return nil
+	} else if err != nil {
+		return nil
+	}
+
+	// operatorkit did not register the metric, so there is nothing to reset.
+	prometheus.Unregister(gauge)
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func Test_resetReconcileErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reconcileErrors := operatorkitReconcileErrors()
+	if reconcileErrors == nil {
+		t.Fatalf("expected reconciliation error metric registered by operatorkit")
+	}
+	reconcileErrors.WithLabelValues("test").Set(3)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- resetReconcileErrors("test", 10*time.Millisecond)(ctx)
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for testutil.ToFloat64(reconcileErrors.WithLabelValues("test")) != 0 {
+		select {
+		case <-deadline:
+			t.Fatalf("expected reconciliation errors to be reset")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	cancel()
+
+	err := <-done
+	if err != nil {
+		t.Fatal(err)
+	}
+}
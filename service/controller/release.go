@@ -1,15 +1,34 @@
 package controller
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	corev1alpha1 "github.com/giantswarm/config-controller/api/v1alpha1"
 	"github.com/giantswarm/k8sclient/v7/pkg/k8sclient"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	"github.com/giantswarm/operatorkit/v7/pkg/controller"
+	"github.com/giantswarm/operatorkit/v7/pkg/controller/collector"
 	"github.com/giantswarm/operatorkit/v7/pkg/resource"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	crcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/giantswarm/release-operator/v4/api/v1alpha1"
 	"github.com/giantswarm/release-operator/v4/pkg/project"
+	"github.com/giantswarm/release-operator/v4/service/controller/inventory"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
 	"github.com/giantswarm/release-operator/v4/service/controller/release"
 )
 
@@ -18,12 +37,39 @@ var (
 )
 
 type ReleaseConfig struct {
-	K8sClient k8sclient.Interface
-	Logger    micrologger.Logger
+	EventRecorder record.EventRecorder
+	Inventory     inventory.Interface
+	K8sClient     k8sclient.Interface
+	Logger        micrologger.Logger
+
+	// DeprecateEndOfLifeReleases makes the controller set spec.state of
+	// Releases to deprecated once their end of life date passed.
+	DeprecateEndOfLifeReleases bool
+	// SkipConfigs disables the creation of Config CRs for all components.
+	// Configs are not watched then.
+	SkipConfigs bool
+	// StatePromotion configures when Releases may become active.
+	StatePromotion key.StatePromotion
+	// WebhookEnabled is whether the mutating webhook is served, so that the
+	// annotations it sets on Releases can be trusted.
+	WebhookEnabled bool
 }
 
+// Release reconciles the status of every single Release CR. Besides the
+// Releases themselves it watches the Apps and Configs managed by
+// release-operator so that status changes reported by app-operator and
+// config-controller are reflected without waiting for the next resync.
 type Release struct {
 	*controller.Controller
+
+	// client reads from the cache of the manager the controller is set up
+	// with.
+	client    client.Reader
+	collector *collector.Set
+	k8sClient k8sclient.Interface
+	logger    micrologger.Logger
+
+	skipConfigs bool
 }
 
 func NewRelease(config ReleaseConfig) (*Release, error) {
@@ -36,8 +82,15 @@ func NewRelease(config ReleaseConfig) (*Release, error) {
 	var resourceSet []resource.Interface
 	{
 		c := release.ResourceSetConfig{
-			K8sClient: config.K8sClient,
-			Logger:    config.Logger,
+			EventRecorder: config.EventRecorder,
+			Inventory:     config.Inventory,
+			K8sClient:     config.K8sClient,
+			Logger:        config.Logger,
+
+			DeprecateEndOfLifeReleases: config.DeprecateEndOfLifeReleases,
+			SkipConfigs:                config.SkipConfigs,
+			StatePromotion:             config.StatePromotion,
+			WebhookEnabled:             config.WebhookEnabled,
 		}
 
 		resourceSet, err = release.NewResourceSet(c)
@@ -64,9 +117,178 @@ func NewRelease(config ReleaseConfig) (*Release, error) {
 		}
 	}
 
+	var collectorSet *collector.Set
+	{
+		c := collector.SetConfig{
+			Logger:     config.Logger,
+			K8sClient:  config.K8sClient,
+			Controller: releaseControllerName,
+
+			NewRuntimeObjectFunc: func() client.Object {
+				return new(v1alpha1.Release)
+			},
+			Selector: labels.Everything(),
+		}
+
+		collectorSet, err = collector.NewSet(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	c := &Release{
 		Controller: releaseController,
+
+		collector: collectorSet,
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+
+		skipConfigs: config.SkipConfigs,
 	}
 
 	return c, nil
 }
+
+// SetupWithManager replaces the boot of the operatorkit controller, which only
+// watches the primary Release objects. The operatorkit controller is still
+// used as the reconciler so that finalizers, pause annotations and resources
+// are handled as before. Its collector and the reset of its reconciliation
+// error metric run with the manager.
+func (r *Release) SetupWithManager(mgr manager.Manager) error {
+	err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		return r.collector.Boot(ctx)
+	}))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = mgr.Add(resetReconcileErrors(releaseControllerName, 4*controller.DefaultResyncPeriod))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.client = mgr.GetClient()
+
+	managedBy := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetLabels()[key.LabelManagedBy] == project.Name()
+	})
+
+	b := builder.
+		ControllerManagedBy(mgr).
+		Named(releaseControllerName).
+		For(new(v1alpha1.Release)).
+		Watches(new(appv1alpha1.App), handler.EnqueueRequestsFromMapFunc(r.mapFunc(mgr.GetClient(), releasesForApp)), builder.WithPredicates(managedBy)).
+		WithOptions(crcontroller.Options{
+			MaxConcurrentReconciles: 1,
+		})
+	if !r.skipConfigs {
+		b = b.Watches(new(corev1alpha1.Config), handler.EnqueueRequestsFromMapFunc(r.mapFunc(mgr.GetClient(), releasesForConfig)), builder.WithPredicates(managedBy))
+	}
+
+	err = b.Complete(r)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// Reconcile wraps the reconciliation of the operatorkit controller and
+// requeues the Release at its end of life date, so that its state is
+// deprecated right away instead of with the next resync.
+func (r *Release) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	res, err := r.Controller.Reconcile(ctx, req)
+	if err != nil {
+		return reconcile.Result{}, microerror.Mask(err)
+	}
+
+	var release v1alpha1.Release
+	err = r.client.Get(ctx, req.NamespacedName, &release)
+	if apierrors.IsNotFound(err) {
+		return res, nil
+	} else if err != nil {
+		return reconcile.Result{}, microerror.Mask(err)
+	}
+
+	until := key.UntilEndOfLife(release, time.Now())
+	if until > 0 && (res.RequeueAfter == 0 || until < res.RequeueAfter) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("requeueing release %#q at its end of life in %s", release.Name, until))
+		res.RequeueAfter = until
+	}
+
+	return res, nil
+}
+
+// mapFunc returns a handler.MapFunc listing all Releases from the cache and
+// passing them to f together with the watched object.
+func (r *Release) mapFunc(c client.Reader, f func(client.Object, []v1alpha1.Release) []reconcile.Request) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var releases v1alpha1.ReleaseList
+		err := c.List(ctx, &releases)
+		if err != nil {
+			r.logger.Errorf(ctx, err, "failed to list releases for %T %#q", obj, obj.GetName())
+			return nil
+		}
+
+		return f(obj, releases.Items)
+	}
+}
+
+// releasesForApp returns a request for every Release having a component or
+// release app deployed by the given App.
+func releasesForApp(obj client.Object, releases []v1alpha1.Release) []reconcile.Request {
+	app, ok := obj.(*appv1alpha1.App)
+	if !ok {
+		return nil
+	}
+
+	return releasesMatching(releases, func(release v1alpha1.Release) bool {
+		for _, component := range key.FilterComponents(release.Spec.Components) {
+			if key.IsSameApp(component, *app) {
+				return true
+			}
+		}
+		for _, releaseApp := range key.FilterApps(release.Spec.Apps) {
+			if key.IsSameReleaseApp(releaseApp, *app) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// releasesForConfig returns a request for every Release having a component
+// configured by the given Config.
+func releasesForConfig(obj client.Object, releases []v1alpha1.Release) []reconcile.Request {
+	config, ok := obj.(*corev1alpha1.Config)
+	if !ok {
+		return nil
+	}
+
+	return releasesMatching(releases, func(release v1alpha1.Release) bool {
+		for _, component := range key.FilterComponents(release.Spec.Components) {
+			if key.IsSameConfig(component, *config) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+func releasesMatching(releases []v1alpha1.Release, matches func(v1alpha1.Release) bool) []reconcile.Request {
+	var requests []reconcile.Request
+	for _, release := range releases {
+		if matches(release) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: release.Namespace,
+					Name:      release.Name,
+				},
+			})
+		}
+	}
+
+	return requests
+}
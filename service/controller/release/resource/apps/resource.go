@@ -1,178 +0,0 @@
-package apps
-
-import (
-	"context"
-	"fmt"
-
-	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
-	corev1alpha1 "github.com/giantswarm/config-controller/api/v1alpha1"
-	"github.com/giantswarm/k8sclient/v7/pkg/k8sclient"
-	"github.com/giantswarm/microerror"
-	"github.com/giantswarm/micrologger"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/labels"
-	"sigs.k8s.io/controller-runtime/pkg/client"
-
-	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
-	"github.com/giantswarm/release-operator/v4/pkg/project"
-	"github.com/giantswarm/release-operator/v4/service/controller/key"
-)
-
-const (
-	Name = "apps"
-)
-
-type Config struct {
-	K8sClient k8sclient.Interface
-	Logger    micrologger.Logger
-}
-
-type Resource struct {
-	k8sClient k8sclient.Interface
-	logger    micrologger.Logger
-}
-
-func New(config Config) (*Resource, error) {
-	if config.K8sClient == nil {
-		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
-	}
-	if config.Logger == nil {
-		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
-	}
-
-	r := &Resource{
-		k8sClient: config.K8sClient,
-		logger:    config.Logger,
-	}
-
-	return r, nil
-}
-
-func (r *Resource) Name() string {
-	return Name
-}
-
-func (r *Resource) ensureState(ctx context.Context) error {
-	var releases releasev1alpha1.ReleaseList
-	{
-		err := r.k8sClient.CtrlClient().List(
-			ctx,
-			&releases,
-		)
-		if err != nil {
-			return microerror.Mask(err)
-		}
-		releases = key.ExcludeDeletedRelease(releases)
-		releases = key.ExcludeUnusedDeprecatedReleases(releases)
-	}
-
-	var components map[string]releasev1alpha1.ReleaseSpecComponent
-	{
-		components = key.ExtractComponents(releases)
-	}
-
-	var apps appv1alpha1.AppList
-	{
-		err := r.k8sClient.CtrlClient().List(
-			ctx,
-			&apps,
-			&client.ListOptions{
-				LabelSelector: labels.SelectorFromSet(labels.Set{
-					key.LabelManagedBy: project.Name(),
-				}),
-			},
-		)
-		if err != nil {
-			return microerror.Mask(err)
-		}
-	}
-
-	var configs corev1alpha1.ConfigList
-	{
-		err := r.k8sClient.CtrlClient().List(
-			ctx,
-			&configs,
-			&client.ListOptions{
-				LabelSelector: labels.SelectorFromSet(labels.Set{
-					key.LabelManagedBy: project.Name(),
-				}),
-			},
-		)
-		if err != nil {
-			return microerror.Mask(err)
-		}
-	}
-
-	appsToDelete := calculateObsoleteApps(components, apps)
-	for i, app := range appsToDelete.Items {
-		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleting app %#q in namespace %#q", app.Name, app.Namespace))
-
-		err := r.k8sClient.CtrlClient().Delete(
-			ctx,
-			&appsToDelete.Items[i],
-		)
-		if apierrors.IsNotFound(err) {
-			// fall through.
-		} else if err != nil {
-			return microerror.Mask(err)
-		}
-
-		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleted app %#q in namespace %#q", app.Name, app.Namespace))
-	}
-
-	appsToCreate := calculateMissingApps(components, apps)
-	for i, app := range appsToCreate.Items {
-		appConfig := key.GetAppConfig(app, configs)
-		if appConfig.ConfigMapRef.Name == "" && appConfig.SecretRef.Name == "" {
-			// Skip this app
-			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("skipping app %#q as its config is not ready", app.Name))
-			continue
-		}
-
-		appsToCreate.Items[i].Spec.Config.ConfigMap.Name = appConfig.ConfigMapRef.Name
-		appsToCreate.Items[i].Spec.Config.ConfigMap.Namespace = appConfig.ConfigMapRef.Namespace
-		appsToCreate.Items[i].Spec.Config.Secret.Name = appConfig.SecretRef.Name
-		appsToCreate.Items[i].Spec.Config.Secret.Namespace = appConfig.SecretRef.Namespace
-
-		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("creating app %#q in namespace %#q", app.Name, app.Namespace))
-
-		err := r.k8sClient.CtrlClient().Create(
-			ctx,
-			&appsToCreate.Items[i],
-		)
-		if apierrors.IsAlreadyExists(err) {
-			// fall through.
-		} else if err != nil {
-			return microerror.Mask(err)
-		}
-
-		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("created app %#q in namespace %#q", app.Name, app.Namespace))
-	}
-
-	return nil
-}
-
-func calculateMissingApps(components map[string]releasev1alpha1.ReleaseSpecComponent, apps appv1alpha1.AppList) appv1alpha1.AppList {
-	var missingApps appv1alpha1.AppList
-
-	for _, component := range components {
-		if !key.ComponentAppCreated(component, apps.Items) {
-			missingApp := key.ConstructApp(component)
-			missingApps.Items = append(missingApps.Items, missingApp)
-		}
-	}
-
-	return missingApps
-}
-
-func calculateObsoleteApps(components map[string]releasev1alpha1.ReleaseSpecComponent, apps appv1alpha1.AppList) appv1alpha1.AppList {
-	var obsoleteApps appv1alpha1.AppList
-
-	for _, app := range apps.Items {
-		if !key.AppReferenced(app, components) {
-			obsoleteApps.Items = append(obsoleteApps.Items, app)
-		}
-	}
-
-	return obsoleteApps
-}
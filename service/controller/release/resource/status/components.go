@@ -0,0 +1,50 @@
+package status
+
+import (
+	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	corev1alpha1 "github.com/giantswarm/config-controller/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
+)
+
+// Returns the deployment state of every given component. The last transition
+// time of a component is only moved forward when its app status changed
+// compared to the previous status. Components whose app does not exist yet
+// list the apps of the components they are waiting for.
+func componentStatuses(components []releasev1alpha1.ReleaseSpecComponent, apps []appv1alpha1.App, configs []corev1alpha1.Config, previous []releasev1alpha1.ReleaseStatusComponent, now metav1.Time) []releasev1alpha1.ReleaseStatusComponent {
+	var statuses []releasev1alpha1.ReleaseStatusComponent
+
+	dependencies := key.ComponentDependencies(components)
+
+	for _, component := range components {
+		s := releasev1alpha1.ReleaseStatusComponent{
+			Name:    component.Name,
+			Version: component.Version,
+		}
+
+		if app, ok := findApp(component, apps); ok {
+			s.AppName = app.Name
+			s.AppStatus = app.Status.Release.Status
+			s.DeployedVersion = app.Status.Version
+		} else {
+			s.WaitingFor = key.UndeployedDependencies(dependencies[key.BuildAppName(component)], apps)
+		}
+		if key.ComponentConfigCreated(component, configs) {
+			s.ConfigName = key.BuildConfigName(component)
+		}
+
+		s.LastTransitionTime = now.DeepCopy()
+		for _, p := range previous {
+			if p.Name == s.Name && p.Version == s.Version && p.AppStatus == s.AppStatus && p.LastTransitionTime != nil {
+				s.LastTransitionTime = p.LastTransitionTime.DeepCopy()
+				break
+			}
+		}
+
+		statuses = append(statuses, s)
+	}
+
+	return statuses
+}
@@ -0,0 +1,254 @@
+package status
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	corev1alpha1 "github.com/giantswarm/config-controller/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
+	"github.com/giantswarm/release-operator/v4/pkg/project"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
+)
+
+// Returns the ComponentsDeployed condition, listing every component whose App
+// is missing or not deployed yet, and what missing Apps are waiting for.
+func componentsDeployedCondition(components []releasev1alpha1.ReleaseSpecComponent, apps []appv1alpha1.App) metav1.Condition {
+	dependencies := key.ComponentDependencies(components)
+
+	var notDeployed []string
+	for _, component := range components {
+		if key.ComponentAppDeployed(component, apps) {
+			continue
+		}
+
+		app, ok := findApp(component, apps)
+		if !ok {
+			waitingFor := key.UndeployedDependencies(dependencies[key.BuildAppName(component)], apps)
+			if len(waitingFor) > 0 {
+				notDeployed = append(notDeployed, fmt.Sprintf("app %#q waiting for %s", key.BuildAppName(component), quoteNames(waitingFor)))
+			} else {
+				notDeployed = append(notDeployed, fmt.Sprintf("app %#q not found", key.BuildAppName(component)))
+			}
+		} else {
+			notDeployed = append(notDeployed, fmt.Sprintf("app %#q has status %#q", app.Name, app.Status.Release.Status))
+		}
+	}
+
+	if len(notDeployed) > 0 {
+		return metav1.Condition{
+			Type:    releasev1alpha1.ConditionComponentsDeployed,
+			Status:  metav1.ConditionFalse,
+			Reason:  releasev1alpha1.ReasonComponentsNotDeployed,
+			Message: strings.Join(notDeployed, ", "),
+		}
+	}
+
+	return metav1.Condition{
+		Type:    releasev1alpha1.ConditionComponentsDeployed,
+		Status:  metav1.ConditionTrue,
+		Reason:  releasev1alpha1.ReasonComponentsDeployed,
+		Message: fmt.Sprintf("%d components deployed", len(components)),
+	}
+}
+
+// Returns the ConfigsReady condition, listing every component whose Config is
+// missing or has not been generated by config-controller yet, together with
+// when the Config was created and what config-controller last reported in
+// its status. Components skipping Config generation are not considered.
+func configsReadyCondition(components []releasev1alpha1.ReleaseSpecComponent, configs corev1alpha1.ConfigList) metav1.Condition {
+	var count int
+	var notReady []string
+	for _, component := range components {
+		if component.SkipConfig {
+			continue
+		}
+		count++
+
+		if !key.ComponentConfigCreated(component, configs.Items) {
+			notReady = append(notReady, fmt.Sprintf("config %#q not found", key.BuildConfigName(component)))
+			continue
+		}
+
+		appConfig := key.GetAppConfig(key.ConstructApp(component), configs)
+		if appConfig.ConfigMapRef.Name == "" && appConfig.SecretRef.Name == "" {
+			message := fmt.Sprintf("config %#q not generated yet", key.BuildConfigName(component))
+			for _, config := range configs.Items {
+				if config.Name != key.BuildConfigName(component) {
+					continue
+				}
+				if !config.CreationTimestamp.IsZero() {
+					message += fmt.Sprintf(" since %s", config.CreationTimestamp.UTC().Format(time.RFC3339))
+				}
+				message += fmt.Sprintf(" (%s)", configStatusReason(config))
+			}
+			notReady = append(notReady, message)
+		}
+	}
+
+	if len(notReady) > 0 {
+		return metav1.Condition{
+			Type:    releasev1alpha1.ConditionConfigsReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  releasev1alpha1.ReasonConfigsNotReady,
+			Message: strings.Join(notReady, ", "),
+		}
+	}
+
+	return metav1.Condition{
+		Type:    releasev1alpha1.ConditionConfigsReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  releasev1alpha1.ReasonConfigsReady,
+		Message: fmt.Sprintf("%d configs ready", count),
+	}
+}
+
+// Returns the AppDependenciesResolved condition, listing every component and
+// release app deployed by release-operator which is part of a dependency
+// cycle.
+func appDependenciesResolvedCondition(release *releasev1alpha1.Release) metav1.Condition {
+	var cycles []string
+	cycles = append(cycles, key.DependencyCycles(key.ComponentDependencies(release.Spec.Components))...)
+	cycles = append(cycles, key.DependencyCycles(key.AppDependencies(release.Spec.Apps))...)
+
+	if len(cycles) > 0 {
+		return metav1.Condition{
+			Type:    releasev1alpha1.ConditionAppDependenciesResolved,
+			Status:  metav1.ConditionFalse,
+			Reason:  releasev1alpha1.ReasonDependencyCycle,
+			Message: fmt.Sprintf("apps %s depend on each other in a cycle", quoteNames(cycles)),
+		}
+	}
+
+	count := len(key.FilterComponents(release.Spec.Components)) + len(key.FilterApps(release.Spec.Apps))
+
+	return metav1.Condition{
+		Type:    releasev1alpha1.ConditionAppDependenciesResolved,
+		Status:  metav1.ConditionTrue,
+		Reason:  releasev1alpha1.ReasonDependenciesResolved,
+		Message: fmt.Sprintf("dependencies of %d apps resolved", count),
+	}
+}
+
+func inUseCondition(inUse bool) metav1.Condition {
+	if inUse {
+		return metav1.Condition{
+			Type:    releasev1alpha1.ConditionInUse,
+			Status:  metav1.ConditionTrue,
+			Reason:  releasev1alpha1.ReasonUsedByClusters,
+			Message: "release is used by at least one cluster",
+		}
+	}
+
+	return metav1.Condition{
+		Type:    releasev1alpha1.ConditionInUse,
+		Status:  metav1.ConditionFalse,
+		Reason:  releasev1alpha1.ReasonNotUsed,
+		Message: "release is not used by any cluster",
+	}
+}
+
+func clusterDiscoveryDegradedCondition(failures []string) metav1.Condition {
+	if len(failures) > 0 {
+		return metav1.Condition{
+			Type:    releasev1alpha1.ConditionClusterDiscoveryDegraded,
+			Status:  metav1.ConditionTrue,
+			Reason:  releasev1alpha1.ReasonDiscoveryFailed,
+			Message: fmt.Sprintf("clusters could not be fully discovered, keeping the previous in use state: %s", strings.Join(failures, ", ")),
+		}
+	}
+
+	return metav1.Condition{
+		Type:    releasev1alpha1.ConditionClusterDiscoveryDegraded,
+		Status:  metav1.ConditionFalse,
+		Reason:  releasev1alpha1.ReasonDiscoverySucceeded,
+		Message: "all clusters were discovered",
+	}
+}
+
+func endOfLifeCondition(release *releasev1alpha1.Release, now time.Time) metav1.Condition {
+	eol := release.Spec.EndOfLifeDate
+
+	switch {
+	case eol == nil:
+		return metav1.Condition{
+			Type:    releasev1alpha1.ConditionEndOfLife,
+			Status:  metav1.ConditionFalse,
+			Reason:  releasev1alpha1.ReasonNoEndOfLifeDate,
+			Message: "release has no end of life date",
+		}
+	case !now.Before(eol.Time):
+		return metav1.Condition{
+			Type:    releasev1alpha1.ConditionEndOfLife,
+			Status:  metav1.ConditionTrue,
+			Reason:  releasev1alpha1.ReasonEndOfLifeReached,
+			Message: fmt.Sprintf("release reached its end of life on %s", eol.UTC().Format(time.RFC3339)),
+		}
+	default:
+		return metav1.Condition{
+			Type:    releasev1alpha1.ConditionEndOfLife,
+			Status:  metav1.ConditionFalse,
+			Reason:  releasev1alpha1.ReasonEndOfLifeNotReached,
+			Message: fmt.Sprintf("release reaches its end of life on %s", eol.UTC().Format(time.RFC3339)),
+		}
+	}
+}
+
+func stateTransitionAcceptedCondition(release *releasev1alpha1.Release, accepted releasev1alpha1.ReleaseState, rejection string) metav1.Condition {
+	if rejection != "" {
+		return metav1.Condition{
+			Type:    releasev1alpha1.ConditionStateTransitionAccepted,
+			Status:  metav1.ConditionFalse,
+			Reason:  releasev1alpha1.ReasonStateRejected,
+			Message: fmt.Sprintf("state %#q was rejected and %#q is kept, spec.state %s", release.Spec.State, accepted, rejection),
+		}
+	}
+
+	return metav1.Condition{
+		Type:    releasev1alpha1.ConditionStateTransitionAccepted,
+		Status:  metav1.ConditionTrue,
+		Reason:  releasev1alpha1.ReasonStateAccepted,
+		Message: fmt.Sprintf("state %#q was accepted", accepted),
+	}
+}
+
+func quoteNames(names []string) string {
+	var quoted []string
+	for _, n := range names {
+		quoted = append(quoted, fmt.Sprintf("%#q", n))
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+func findApp(component releasev1alpha1.ReleaseSpecComponent, apps []appv1alpha1.App) (appv1alpha1.App, bool) {
+	for _, a := range apps {
+		if key.IsSameApp(component, a) {
+			return a, true
+		}
+	}
+
+	return appv1alpha1.App{}, false
+}
+
+// configStatusReason describes why the given Config is not generated based on
+// the status config-controller reported. config-controller does not report
+// errors in the status, so the reason is derived from the app and config
+// repository version it last generated the configuration for.
+func configStatusReason(config corev1alpha1.Config) string {
+	status := config.Status
+	if status.App == (corev1alpha1.ConfigStatusApp{}) && status.Version == "" {
+		return "not reconciled by config-controller yet"
+	}
+	if status.App != corev1alpha1.ConfigStatusApp(config.Spec.App) {
+		return fmt.Sprintf("last generated for %#q version %#q from config version %#q", status.App.Name, status.App.Version, status.Version)
+	}
+	if status.Config.ConfigMapRef.Name == "" && status.Config.SecretRef.Name == "" {
+		return fmt.Sprintf("no ConfigMap or Secret reported from config version %#q", status.Version)
+	}
+
+	return fmt.Sprintf("generated from config version %#q but not managed by %s", status.Version, project.Name())
+}
@@ -3,15 +3,21 @@ package status
 import (
 	"context"
 	"fmt"
-	"strings"
+	"sort"
+	"time"
 
 	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	corev1alpha1 "github.com/giantswarm/config-controller/api/v1alpha1"
 	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
 	"github.com/giantswarm/release-operator/v4/pkg/project"
+	"github.com/giantswarm/release-operator/v4/service/controller/inventory"
 	"github.com/giantswarm/release-operator/v4/service/controller/key"
 )
 
@@ -26,6 +32,11 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 	}
 
 	components := key.FilterComponents(release.Spec.Components)
+	if r.skipConfigs {
+		for i := range components {
+			components[i].SkipConfig = true
+		}
+	}
 
 	var apps appv1alpha1.AppList
 	{
@@ -43,54 +54,110 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		}
 	}
 
-	// Doing this per-release isn't ideal, can we pass this list to each status reconcile somehow?
-	var tenantClusters []tenantCluster
+	var configs corev1alpha1.ConfigList
+	if !r.skipConfigs {
+		err := r.k8sClient.CtrlClient().List(
+			ctx,
+			&configs,
+			&client.ListOptions{
+				LabelSelector: labels.SelectorFromSet(labels.Set{
+					key.LabelManagedBy: project.Name(),
+				}),
+			},
+		)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	var snapshot inventory.Snapshot
+	var discoveryFailures []string
 	{
 		r.logger.LogCtx(ctx, "level", "debug", "message", "searching for running tenant clusters")
 
 		var err error
-		tenantClusters, err = r.getCurrentTenantClusters(ctx)
+		snapshot, err = r.inventory.Snapshot(ctx)
 		if err != nil {
 			r.logger.LogCtx(ctx, "level", "error", "message", fmt.Sprintf("error finding tenant clusters: %s", err))
+			discoveryFailures = []string{err.Error()}
 		} else {
-			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found %d running tenant clusters", len(tenantClusters)))
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found %d running tenant clusters", len(snapshot.Clusters)))
+			discoveryFailures = snapshot.Failures
 		}
 	}
 
-	var releaseInUse bool
+	var inUseBy []releasev1alpha1.ReleaseStatusCluster
 	{
-		// Get two sets of just deduplicated versions
-		releaseVersions, operatorVersions := consolidateClusterVersions(tenantClusters)
-		// Check the set of release versions and keep this release if it is used.
 		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("checking release %s", release.Name))
-		if releaseVersions[strings.TrimPrefix(release.Name, "v")] { // The release name has a leading `v`
-			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("keeping release %s because it is explicitly used", release.Name))
-			releaseInUse = true
-		} else {
-			for _, o := range key.GetProviderOperators() {
-				operatorVersion := getOperatorVersionInRelease(o, release)
-				// Check the set of operator versions and keep this release if its operator version is used.
-				if operatorVersion != "" && operatorVersions[o][operatorVersion] {
-					r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("keeping release %s because a cluster using its operator version (%s) is present", release.Name, operatorVersion))
-					releaseInUse = true
-				}
-			}
+
+		inUseBy = clustersUsingRelease(release, snapshot.Clusters)
+		for _, c := range inUseBy {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("keeping release %s because cluster %s/%s uses it (%s)", release.Name, c.Namespace, c.Name, c.Reason))
 		}
 
 		// We don't want to unschedule a kvm-operator with no releases in use as long as it has pods it needs to drain.
 		// We check the kvm-operator version (blank if this is an AWS/Azure installation), find all pods with that
-		// version bundle version annotation and consider the release to be in use if any were found.
-		if !releaseInUse { // small optimization
+		// version bundle version annotation and consider the release to be in use by the clusters of their namespaces.
+		if len(inUseBy) == 0 { // small optimization
 			operatorVersion := getOperatorVersionInRelease(key.ProviderOperatorKVM, release)
 			if operatorVersion != "" { // only execute on KVM
-				kvmPodsExist, err := r.getKVMOperatorVersionPodsExist(ctx, operatorVersion)
-				if err != nil {
-					return microerror.Mask(err)
-				}
-				if kvmPodsExist {
-					releaseInUse = true
-				}
+				inUseBy = clustersWithKVMPods(snapshot.KVMOperatorPods(operatorVersion))
+			}
+		}
+
+		sort.Slice(inUseBy, func(i, j int) bool {
+			if inUseBy[i].Namespace != inUseBy[j].Namespace {
+				return inUseBy[i].Namespace < inUseBy[j].Namespace
+			}
+			return inUseBy[i].Name < inUseBy[j].Name
+		})
+	}
+	releaseInUse := len(inUseBy) > 0
+
+	// Clusters using the release may be missing when discovery failed. The
+	// release must not be considered unused then, as this would remove the
+	// components of deprecated releases clusters still depend on.
+	if len(discoveryFailures) > 0 && !releaseInUse && release.Status.InUse {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("keeping release %#q in use as cluster discovery is degraded", release.Name))
+
+		inUseBy = release.Status.InUseBy
+		releaseInUse = true
+	}
+
+	now := time.Now()
+	state := key.EffectiveState(*release, now)
+	if key.EndOfLifeReached(*release, now) {
+		if r.deprecateEndOfLife && release.Spec.State != releasev1alpha1.StateDeprecated {
+			r.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("deprecating release %#q as it reached its end of life", release.Name))
+
+			patch := client.MergeFrom(release.DeepCopy())
+			if release.Annotations == nil {
+				release.Annotations = map[string]string{}
 			}
+			release.Annotations[key.StateChangedByAnnotation] = project.Name()
+			release.Annotations[key.StateChangedToAnnotation] = string(releasev1alpha1.StateDeprecated)
+			release.Spec.State = releasev1alpha1.StateDeprecated
+			err := r.k8sClient.CtrlClient().Patch(ctx, release, patch)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			r.eventRecorder.Eventf(release, corev1.EventTypeNormal, "Deprecated", "Release %#q was deprecated as it reached its end of life on %s", release.Name, release.Spec.EndOfLifeDate.UTC().Format(time.RFC3339))
+		}
+
+		if releaseInUse {
+			r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("release %#q reached its end of life but is still used by %d clusters", release.Name, len(inUseBy)))
+			r.eventRecorder.Eventf(release, corev1.EventTypeWarning, "EndOfLifeInUse", "Release %#q reached its end of life on %s but is still used by %d clusters", release.Name, release.Spec.EndOfLifeDate.UTC().Format(time.RFC3339), len(inUseBy))
+		}
+	}
+
+	accepted, rejection := r.acceptedState(release, now)
+	if rejection != "" {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("keeping state of release %#q at %#q as spec.state %#q %s", release.Name, accepted, release.Spec.State, rejection))
+		r.eventRecorder.Eventf(release, corev1.EventTypeWarning, "StateRejected", "State of release %#q is kept at %#q as spec.state %#q %s", release.Name, accepted, release.Spec.State, rejection)
+
+		if !key.EndOfLifeReached(*release, now) {
+			state = accepted
 		}
 	}
 
@@ -109,8 +176,29 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 	{
 		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("setting status for release %#q", release.Name))
 
+		conditions := []metav1.Condition{
+			componentsDeployedCondition(components, apps.Items),
+			configsReadyCondition(components, configs),
+			appDependenciesResolvedCondition(release),
+			inUseCondition(releaseInUse),
+			clusterDiscoveryDegradedCondition(discoveryFailures),
+			endOfLifeCondition(release, now),
+			stateTransitionAcceptedCondition(release, accepted, rejection),
+		}
+		for _, c := range conditions {
+			c.ObservedGeneration = release.Generation
+			meta.SetStatusCondition(&release.Status.Conditions, c)
+		}
+
+		release.Status.Components = componentStatuses(components, apps.Items, configs.Items, release.Status.Components, metav1.Now())
+		release.Status.ObservedGeneration = release.Generation
+		release.Status.InUseBy = inUseBy
+		release.Status.ClusterCount = len(inUseBy)
 		release.Status.Ready = releaseDeployed
+		release.Status.LastUsedTime = lastUsedTime(release, releaseInUse, metav1.NewTime(now))
 		release.Status.InUse = releaseInUse
+		release.Status.State = state
+		release.Status.StateHistory = stateHistory(release, accepted, r.webhookEnabled, metav1.NewTime(now))
 		err := r.k8sClient.CtrlClient().Status().Update(
 			ctx,
 			release,
@@ -134,3 +222,80 @@ func getOperatorVersionInRelease(operator string, release *releasev1alpha1.Relea
 	}
 	return ""
 }
+
+// lastUsedTime returns the time the given release was last observed in use.
+// It is only set once the release stops being used, or when it is first
+// observed unused, so that the status does not change on every
+// reconciliation of a release in use.
+func lastUsedTime(release *releasev1alpha1.Release, inUse bool, now metav1.Time) *metav1.Time {
+	if inUse {
+		return nil
+	}
+	if release.Status.InUse || release.Status.LastUsedTime == nil {
+		return &now
+	}
+
+	return release.Status.LastUsedTime
+}
+
+// acceptedState returns the state of the given release after validating the
+// change of spec.state since the last accepted state. When the change is not
+// allowed, the last accepted state is returned together with the reason. This
+// enforces the state machine also when the admission webhook is disabled.
+//
+// Releases observed for the first time have no state history yet. Their
+// spec.state is accepted as is to seed the history, so that existing releases
+// are not demoted when promotion is enforced. Only deprecated releases are
+// still kept from moving back to wip.
+func (r *Resource) acceptedState(release *releasev1alpha1.Release, now time.Time) (releasev1alpha1.ReleaseState, string) {
+	from := release.Status.State
+	promotion := r.statePromotion
+	if len(release.Status.StateHistory) > 0 {
+		from = release.Status.StateHistory[len(release.Status.StateHistory)-1].To
+	} else {
+		promotion = key.StatePromotion{}
+	}
+	if from == "" {
+		return release.Spec.State, ""
+	}
+
+	rejection := key.StateTransitionError(*release, from, release.Spec.State, promotion, now)
+	if rejection == "" {
+		return release.Spec.State, ""
+	}
+
+	return from, rejection
+}
+
+// stateHistory returns the state history of the given release with a
+// transition to the given accepted state appended when it changed since the
+// last recorded one. When the mutating webhook is enabled, the user is taken
+// from the annotations it sets if they belong to the accepted state. Only the
+// most recent transitions are kept.
+func stateHistory(release *releasev1alpha1.Release, to releasev1alpha1.ReleaseState, webhookEnabled bool, now metav1.Time) []releasev1alpha1.ReleaseStatusStateTransition {
+	history := release.Status.StateHistory
+
+	var from releasev1alpha1.ReleaseState
+	if len(history) > 0 {
+		from = history[len(history)-1].To
+	}
+	if len(history) > 0 && from == to {
+		return history
+	}
+
+	transition := releasev1alpha1.ReleaseStatusStateTransition{
+		From: from,
+		To:   to,
+		Time: now,
+	}
+	if webhookEnabled && release.Annotations[key.StateChangedToAnnotation] == string(to) {
+		transition.User = release.Annotations[key.StateChangedByAnnotation]
+	}
+
+	history = append(history, transition)
+	if len(history) > maxStateHistory {
+		history = history[len(history)-maxStateHistory:]
+	}
+
+	return history
+}
@@ -2,68 +2,642 @@ package status
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"testing"
+	"time"
 
+	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	corev1alpha1 "github.com/giantswarm/config-controller/api/v1alpha1"
 	"github.com/giantswarm/k8sclient/v7/pkg/k8sclienttest"
+	"github.com/giantswarm/micrologger/microloggertest"
 	"github.com/google/go-cmp/cmp"
-	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
+	"github.com/giantswarm/release-operator/v4/service/controller/inventory"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
 )
 
-var testClusters = []tenantCluster{
+var testClusters = []inventory.Cluster{
 	{
 		ID:               "abc12",
+		Namespace:        "default",
 		OperatorVersion:  "1.2.3",
 		ProviderOperator: "test-operator",
 		ReleaseVersion:   "9.8.7",
 	},
 	{
 		ID:               "def34",
+		Namespace:        "default",
 		OperatorVersion:  "4.5.6",
 		ProviderOperator: "test-operator",
 		ReleaseVersion:   "8.7.6",
 	},
 	// These next two clusters differ only in their IDs.
-	// The versions should be deduplicated in the result map.
 	{
 		ID:               "ghi56",
+		Namespace:        "org-acme",
 		OperatorVersion:  "1.2.1-4",
 		ProviderOperator: "another-operator",
 		ReleaseVersion:   "9.8.9-1",
 	},
 	{
 		ID:               "jkl78",
+		Namespace:        "org-acme",
 		OperatorVersion:  "1.2.1-4",
 		ProviderOperator: "another-operator",
 		ReleaseVersion:   "9.8.9-1",
 	},
 }
 
-func Test_consolidateClusterVersions(t *testing.T) {
+func Test_clustersUsingRelease(t *testing.T) {
+	testCases := []struct {
+		name     string
+		release  *releasev1alpha1.Release
+		expected []releasev1alpha1.ReleaseStatusCluster
+	}{
+		{
+			name: "case 0: clusters with matching release version label are listed",
+			release: &releasev1alpha1.Release{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "v9.8.9-1",
+				},
+			},
+			expected: []releasev1alpha1.ReleaseStatusCluster{
+				{
+					Namespace: "org-acme",
+					Name:      "ghi56",
+					Reason:    releasev1alpha1.ClusterUsageReasonReleaseLabel,
+				},
+				{
+					Namespace: "org-acme",
+					Name:      "jkl78",
+					Reason:    releasev1alpha1.ClusterUsageReasonReleaseLabel,
+				},
+			},
+		},
+		{
+			name: "case 1: clusters with matching operator version are listed",
+			release: &releasev1alpha1.Release{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "v1.0.0",
+				},
+				Spec: releasev1alpha1.ReleaseSpec{
+					Components: []releasev1alpha1.ReleaseSpecComponent{
+						{
+							Name:    "test-operator",
+							Version: "4.5.6",
+						},
+					},
+				},
+			},
+			expected: []releasev1alpha1.ReleaseStatusCluster{
+				{
+					Namespace: "default",
+					Name:      "def34",
+					Reason:    releasev1alpha1.ClusterUsageReasonOperatorVersion,
+				},
+			},
+		},
+		{
+			name: "case 2: release label takes precedence over operator version",
+			release: &releasev1alpha1.Release{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "v9.8.7",
+				},
+				Spec: releasev1alpha1.ReleaseSpec{
+					Components: []releasev1alpha1.ReleaseSpecComponent{
+						{
+							Name:    "test-operator",
+							Version: "1.2.3",
+						},
+					},
+				},
+			},
+			expected: []releasev1alpha1.ReleaseStatusCluster{
+				{
+					Namespace: "default",
+					Name:      "abc12",
+					Reason:    releasev1alpha1.ClusterUsageReasonReleaseLabel,
+				},
+			},
+		},
+		{
+			name: "case 3: unused release has no clusters",
+			release: &releasev1alpha1.Release{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "v1.0.0",
+				},
+			},
+			expected: nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			result := clustersUsingRelease(tc.release, testClusters)
+			if !cmp.Equal(result, tc.expected) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expected, result))
+			}
+		})
+	}
+}
+
+func Test_clustersWithKVMPods(t *testing.T) {
+	pods := []metav1.PartialObjectMetadata{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "master-abc12-1",
+				Namespace: "abc12",
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "worker-abc12-1",
+				Namespace: "abc12",
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "master-def34-1",
+				Namespace: "def34",
+			},
+		},
+	}
+
+	expected := []releasev1alpha1.ReleaseStatusCluster{
+		{
+			Name:   "abc12",
+			Reason: releasev1alpha1.ClusterUsageReasonKVMPods,
+		},
+		{
+			Name:   "def34",
+			Reason: releasev1alpha1.ClusterUsageReasonKVMPods,
+		},
+	}
+
+	result := clustersWithKVMPods(pods)
+	if !cmp.Equal(result, expected) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(expected, result))
+	}
+}
+
+func Test_componentsDeployedCondition(t *testing.T) {
+	components := []releasev1alpha1.ReleaseSpecComponent{
+		{
+			Catalog:               "control-plane-catalog",
+			Name:                  "aws-operator",
+			ReleaseOperatorDeploy: true,
+			Version:               "1.0.0",
+		},
+		{
+			Catalog:               "control-plane-catalog",
+			Name:                  "cluster-operator",
+			ReleaseOperatorDeploy: true,
+			Version:               "2.0.0",
+		},
+	}
+
+	deployedApp := func(component releasev1alpha1.ReleaseSpecComponent, status string) appv1alpha1.App {
+		app := key.ConstructApp(component)
+		app.Status.Release.Status = status
+		return app
+	}
+
+	testCases := []struct {
+		name              string
+		apps              []appv1alpha1.App
+		expectedCondition metav1.Condition
+	}{
+		{
+			name: "case 0: all components deployed",
+			apps: []appv1alpha1.App{
+				deployedApp(components[0], key.AppStatusDeployed),
+				deployedApp(components[1], key.AppStatusDeployed),
+			},
+			expectedCondition: metav1.Condition{
+				Type:    releasev1alpha1.ConditionComponentsDeployed,
+				Status:  metav1.ConditionTrue,
+				Reason:  releasev1alpha1.ReasonComponentsDeployed,
+				Message: "2 components deployed",
+			},
+		},
+		{
+			name: "case 1: one app missing and one app failed",
+			apps: []appv1alpha1.App{
+				deployedApp(components[1], "failed"),
+			},
+			expectedCondition: metav1.Condition{
+				Type:    releasev1alpha1.ConditionComponentsDeployed,
+				Status:  metav1.ConditionFalse,
+				Reason:  releasev1alpha1.ReasonComponentsNotDeployed,
+				Message: "app `aws-operator-1.0.0` not found, app `cluster-operator-2.0.0` has status `failed`",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			result := componentsDeployedCondition(components, tc.apps)
+			if !cmp.Equal(result, tc.expectedCondition) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedCondition, result))
+			}
+		})
+	}
+}
+
+func Test_componentsDeployedCondition_waitingFor(t *testing.T) {
+	components := []releasev1alpha1.ReleaseSpecComponent{
+		{
+			Catalog:               "control-plane-catalog",
+			Name:                  "cert-operator",
+			ReleaseOperatorDeploy: true,
+			Version:               "1.0.0",
+		},
+		{
+			Catalog:               "control-plane-catalog",
+			DependsOn:             []string{"cert-operator", "kubernetes"},
+			Name:                  "cluster-operator",
+			ReleaseOperatorDeploy: true,
+			Version:               "2.0.0",
+		},
+		{
+			Name:    "kubernetes",
+			Version: "1.18.0",
+		},
+	}
+
+	app := key.ConstructApp(components[0])
+	app.Status.Release.Status = "pending-install"
+
+	result := componentsDeployedCondition(key.FilterComponents(components), []appv1alpha1.App{app})
+
+	expectedMessage := "app `cert-operator-1.0.0` has status `pending-install`, app `cluster-operator-2.0.0` waiting for `cert-operator-1.0.0`"
+	if !cmp.Equal(result.Message, expectedMessage) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(expectedMessage, result.Message))
+	}
+
+	statuses := componentStatuses(key.FilterComponents(components), []appv1alpha1.App{app}, nil, nil, metav1.Now())
+	if !cmp.Equal(statuses[0].WaitingFor, []string(nil)) {
+		t.Fatalf("\n\n%s\n", cmp.Diff([]string(nil), statuses[0].WaitingFor))
+	}
+	if !cmp.Equal(statuses[1].WaitingFor, []string{"cert-operator-1.0.0"}) {
+		t.Fatalf("\n\n%s\n", cmp.Diff([]string{"cert-operator-1.0.0"}, statuses[1].WaitingFor))
+	}
+}
+
+func Test_configsReadyCondition(t *testing.T) {
+	component := releasev1alpha1.ReleaseSpecComponent{
+		Catalog:               "control-plane-catalog",
+		Name:                  "aws-operator",
+		ReleaseOperatorDeploy: true,
+		Version:               "1.0.0",
+	}
+
+	generatedConfig := func() corev1alpha1.Config {
+		config := key.ConstructConfig(component)
+		config.Status.App = corev1alpha1.ConfigStatusApp{
+			Catalog: component.Catalog,
+			Name:    component.Name,
+			Version: component.Version,
+		}
+		config.Status.Config.ConfigMapRef.Name = "aws-operator-1.0.0-abc12"
+		return config
+	}
+
 	testCases := []struct {
 		name              string
-		clusters          []tenantCluster
-		expectedReleases  map[string]bool
-		expectedOperators map[string]map[string]bool
+		skipConfig        bool
+		configs           []corev1alpha1.Config
+		expectedCondition metav1.Condition
 	}{
 		{
-			name:     "case 0: release and operator versions are all present in map",
-			clusters: testClusters,
-			expectedReleases: map[string]bool{
-				"9.8.7":   true,
-				"8.7.6":   true,
-				"9.8.9-1": true,
+			name:    "case 0: config generated",
+			configs: []corev1alpha1.Config{generatedConfig()},
+			expectedCondition: metav1.Condition{
+				Type:    releasev1alpha1.ConditionConfigsReady,
+				Status:  metav1.ConditionTrue,
+				Reason:  releasev1alpha1.ReasonConfigsReady,
+				Message: "1 configs ready",
+			},
+		},
+		{
+			name:    "case 1: config not generated yet",
+			configs: []corev1alpha1.Config{key.ConstructConfig(component)},
+			expectedCondition: metav1.Condition{
+				Type:    releasev1alpha1.ConditionConfigsReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  releasev1alpha1.ReasonConfigsNotReady,
+				Message: "config `aws-operator-1.0.0` not generated yet (not reconciled by config-controller yet)",
+			},
+		},
+		{
+			name:    "case 2: config missing",
+			configs: nil,
+			expectedCondition: metav1.Condition{
+				Type:    releasev1alpha1.ConditionConfigsReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  releasev1alpha1.ReasonConfigsNotReady,
+				Message: "config `aws-operator-1.0.0` not found",
+			},
+		},
+		{
+			name: "case 3: config not generated yet since its creation",
+			configs: []corev1alpha1.Config{
+				func() corev1alpha1.Config {
+					config := key.ConstructConfig(component)
+					config.CreationTimestamp = metav1.NewTime(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+					return config
+				}(),
+			},
+			expectedCondition: metav1.Condition{
+				Type:    releasev1alpha1.ConditionConfigsReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  releasev1alpha1.ReasonConfigsNotReady,
+				Message: "config `aws-operator-1.0.0` not generated yet since 2026-01-02T03:04:05Z (not reconciled by config-controller yet)",
+			},
+		},
+		{
+			name: "case 4: config generated for a previous app version",
+			configs: []corev1alpha1.Config{
+				func() corev1alpha1.Config {
+					config := generatedConfig()
+					config.Status.App.Version = "0.9.0"
+					config.Status.Version = "main"
+					return config
+				}(),
 			},
-			expectedOperators: map[string]map[string]bool{
-				"test-operator": map[string]bool{
-					"1.2.3": true,
-					"4.5.6": true,
+			expectedCondition: metav1.Condition{
+				Type:    releasev1alpha1.ConditionConfigsReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  releasev1alpha1.ReasonConfigsNotReady,
+				Message: "config `aws-operator-1.0.0` not generated yet (last generated for `aws-operator` version `0.9.0` from config version `main`)",
+			},
+		},
+		{
+			name: "case 5: config reconciled without ConfigMap or Secret",
+			configs: []corev1alpha1.Config{
+				func() corev1alpha1.Config {
+					config := generatedConfig()
+					config.Status.Config.ConfigMapRef.Name = ""
+					config.Status.Version = "main"
+					return config
+				}(),
+			},
+			expectedCondition: metav1.Condition{
+				Type:    releasev1alpha1.ConditionConfigsReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  releasev1alpha1.ReasonConfigsNotReady,
+				Message: "config `aws-operator-1.0.0` not generated yet (no ConfigMap or Secret reported from config version `main`)",
+			},
+		},
+		{
+			name:       "case 6: component skipping config generation",
+			skipConfig: true,
+			configs:    nil,
+			expectedCondition: metav1.Condition{
+				Type:    releasev1alpha1.ConditionConfigsReady,
+				Status:  metav1.ConditionTrue,
+				Reason:  releasev1alpha1.ReasonConfigsReady,
+				Message: "0 configs ready",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			c := component
+			c.SkipConfig = tc.skipConfig
+
+			result := configsReadyCondition([]releasev1alpha1.ReleaseSpecComponent{c}, corev1alpha1.ConfigList{Items: tc.configs})
+			if !cmp.Equal(result, tc.expectedCondition) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedCondition, result))
+			}
+		})
+	}
+}
+
+func Test_appDependenciesResolvedCondition(t *testing.T) {
+	testCases := []struct {
+		name            string
+		apps            []releasev1alpha1.ReleaseSpecApp
+		components      []releasev1alpha1.ReleaseSpecComponent
+		expectedStatus  metav1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name: "case 0: apps without cycle",
+			apps: []releasev1alpha1.ReleaseSpecApp{
+				{Name: "a", Version: "1.0.0", ReleaseOperatorDeploy: true, DependsOn: []string{"b"}},
+				{Name: "b", Version: "1.0.0", ReleaseOperatorDeploy: true},
+			},
+			expectedStatus:  metav1.ConditionTrue,
+			expectedReason:  releasev1alpha1.ReasonDependenciesResolved,
+			expectedMessage: "dependencies of 2 apps resolved",
+		},
+		{
+			name: "case 1: apps depending on each other",
+			apps: []releasev1alpha1.ReleaseSpecApp{
+				{Name: "a", Version: "1.0.0", ReleaseOperatorDeploy: true, DependsOn: []string{"b"}},
+				{Name: "b", Version: "1.0.0", ReleaseOperatorDeploy: true, DependsOn: []string{"a"}},
+				{Name: "c", Version: "1.0.0", ReleaseOperatorDeploy: true, DependsOn: []string{"a"}},
+			},
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  releasev1alpha1.ReasonDependencyCycle,
+			expectedMessage: "apps `a-1.0.0`, `b-1.0.0` depend on each other in a cycle",
+		},
+		{
+			name: "case 2: cycle between apps not deployed by release-operator is ignored",
+			apps: []releasev1alpha1.ReleaseSpecApp{
+				{Name: "a", Version: "1.0.0", DependsOn: []string{"b"}},
+				{Name: "b", Version: "1.0.0", DependsOn: []string{"a"}},
+			},
+			expectedStatus:  metav1.ConditionTrue,
+			expectedReason:  releasev1alpha1.ReasonDependenciesResolved,
+			expectedMessage: "dependencies of 0 apps resolved",
+		},
+		{
+			name: "case 3: components depending on each other",
+			components: []releasev1alpha1.ReleaseSpecComponent{
+				{Name: "x", Version: "1.0.0", ReleaseOperatorDeploy: true, DependsOn: []string{"y"}},
+				{Name: "y", Version: "2.0.0", ReleaseOperatorDeploy: true, DependsOn: []string{"x"}},
+			},
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  releasev1alpha1.ReasonDependencyCycle,
+			expectedMessage: "apps `x-1.0.0`, `y-2.0.0` depend on each other in a cycle",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			release := &releasev1alpha1.Release{
+				Spec: releasev1alpha1.ReleaseSpec{
+					Apps:       tc.apps,
+					Components: tc.components,
 				},
-				"another-operator": map[string]bool{
-					"1.2.1-4": true,
+			}
+
+			result := appDependenciesResolvedCondition(release)
+			if !cmp.Equal(result.Status, tc.expectedStatus) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedStatus, result.Status))
+			}
+			if !cmp.Equal(result.Reason, tc.expectedReason) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedReason, result.Reason))
+			}
+			if !cmp.Equal(result.Message, tc.expectedMessage) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedMessage, result.Message))
+			}
+		})
+	}
+}
+
+func Test_endOfLifeCondition(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		endOfLifeDate  *metav1.Time
+		expectedStatus metav1.ConditionStatus
+		expectedReason string
+	}{
+		{
+			name:           "case 0: no end of life date",
+			endOfLifeDate:  nil,
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: releasev1alpha1.ReasonNoEndOfLifeDate,
+		},
+		{
+			name:           "case 1: end of life date in the future",
+			endOfLifeDate:  &metav1.Time{Time: now.Add(time.Hour)},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: releasev1alpha1.ReasonEndOfLifeNotReached,
+		},
+		{
+			name:           "case 2: end of life date in the past",
+			endOfLifeDate:  &metav1.Time{Time: now.Add(-time.Hour)},
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: releasev1alpha1.ReasonEndOfLifeReached,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			release := &releasev1alpha1.Release{
+				Spec: releasev1alpha1.ReleaseSpec{
+					EndOfLifeDate: tc.endOfLifeDate,
+				},
+			}
+
+			result := endOfLifeCondition(release, now)
+			if !cmp.Equal(result.Status, tc.expectedStatus) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedStatus, result.Status))
+			}
+			if !cmp.Equal(result.Reason, tc.expectedReason) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedReason, result.Reason))
+			}
+		})
+	}
+}
+
+func Test_componentStatuses(t *testing.T) {
+	previousTime := metav1.NewTime(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	now := metav1.NewTime(time.Date(2026, 3, 1, 13, 0, 0, 0, time.UTC))
+
+	components := []releasev1alpha1.ReleaseSpecComponent{
+		{
+			Catalog:               "control-plane-catalog",
+			Name:                  "aws-operator",
+			ReleaseOperatorDeploy: true,
+			Version:               "1.0.0",
+		},
+		{
+			Catalog:               "control-plane-catalog",
+			Name:                  "cluster-operator",
+			ReleaseOperatorDeploy: true,
+			Version:               "2.0.0",
+		},
+	}
+
+	app := key.ConstructApp(components[0])
+	app.Status.Release.Status = key.AppStatusDeployed
+	app.Status.Version = "1.0.0"
+
+	testCases := []struct {
+		name             string
+		apps             []appv1alpha1.App
+		configs          []corev1alpha1.Config
+		previous         []releasev1alpha1.ReleaseStatusComponent
+		expectedStatuses []releasev1alpha1.ReleaseStatusComponent
+	}{
+		{
+			name:    "case 0: one component deployed, one component without app and config",
+			apps:    []appv1alpha1.App{app},
+			configs: []corev1alpha1.Config{key.ConstructConfig(components[0])},
+			expectedStatuses: []releasev1alpha1.ReleaseStatusComponent{
+				{
+					Name:               "aws-operator",
+					Version:            "1.0.0",
+					AppName:            "aws-operator-1.0.0",
+					ConfigName:         "aws-operator-1.0.0",
+					AppStatus:          key.AppStatusDeployed,
+					DeployedVersion:    "1.0.0",
+					LastTransitionTime: &now,
+				},
+				{
+					Name:               "cluster-operator",
+					Version:            "2.0.0",
+					LastTransitionTime: &now,
+				},
+			},
+		},
+		{
+			name:    "case 1: transition time is kept when the app status did not change",
+			apps:    []appv1alpha1.App{app},
+			configs: []corev1alpha1.Config{key.ConstructConfig(components[0])},
+			previous: []releasev1alpha1.ReleaseStatusComponent{
+				{
+					Name:               "aws-operator",
+					Version:            "1.0.0",
+					AppStatus:          "pending-install",
+					LastTransitionTime: &previousTime,
+				},
+				{
+					Name:               "cluster-operator",
+					Version:            "2.0.0",
+					LastTransitionTime: &previousTime,
+				},
+			},
+			expectedStatuses: []releasev1alpha1.ReleaseStatusComponent{
+				{
+					Name:               "aws-operator",
+					Version:            "1.0.0",
+					AppName:            "aws-operator-1.0.0",
+					ConfigName:         "aws-operator-1.0.0",
+					AppStatus:          key.AppStatusDeployed,
+					DeployedVersion:    "1.0.0",
+					LastTransitionTime: &now,
+				},
+				{
+					Name:               "cluster-operator",
+					Version:            "2.0.0",
+					LastTransitionTime: &previousTime,
 				},
 			},
 		},
@@ -73,115 +647,462 @@ func Test_consolidateClusterVersions(t *testing.T) {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			t.Log(tc.name)
 
-			resultReleases, resultOperators := consolidateClusterVersions(tc.clusters)
-			if !cmp.Equal(resultReleases, tc.expectedReleases) {
-				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedReleases, resultReleases))
+			result := componentStatuses(components, tc.apps, tc.configs, tc.previous, now)
+			if !cmp.Equal(result, tc.expectedStatuses) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedStatuses, result))
 			}
-			if !cmp.Equal(resultOperators, tc.expectedOperators) {
-				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedOperators, resultOperators))
+		})
+	}
+}
+
+func Test_lastUsedTime(t *testing.T) {
+	now := metav1.NewTime(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	earlier := metav1.NewTime(now.Add(-time.Hour))
+
+	testCases := []struct {
+		name             string
+		status           releasev1alpha1.ReleaseStatus
+		inUse            bool
+		expectedLastUsed *metav1.Time
+	}{
+		{
+			name:             "case 0: release in use has no last used time",
+			status:           releasev1alpha1.ReleaseStatus{InUse: true, LastUsedTime: &earlier},
+			inUse:            true,
+			expectedLastUsed: nil,
+		},
+		{
+			name:             "case 1: release which stopped being used was last used now",
+			status:           releasev1alpha1.ReleaseStatus{InUse: true},
+			inUse:            false,
+			expectedLastUsed: &now,
+		},
+		{
+			name:             "case 2: release first observed unused was last used now",
+			status:           releasev1alpha1.ReleaseStatus{},
+			inUse:            false,
+			expectedLastUsed: &now,
+		},
+		{
+			name:             "case 3: release still unused keeps its last used time",
+			status:           releasev1alpha1.ReleaseStatus{LastUsedTime: &earlier},
+			inUse:            false,
+			expectedLastUsed: &earlier,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			release := &releasev1alpha1.Release{
+				Status: tc.status,
+			}
+
+			result := lastUsedTime(release, tc.inUse, now)
+			if !cmp.Equal(result, tc.expectedLastUsed) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedLastUsed, result))
 			}
 		})
 	}
 }
 
-func Test_getKVMOperatorVersionPodsExist(t *testing.T) {
+func Test_stateHistory(t *testing.T) {
+	now := metav1.NewTime(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	earlier := metav1.NewTime(now.Add(-time.Hour))
+
 	testCases := []struct {
 		name            string
-		pods            []runtime.Object
-		operatorVersion string
-		expectedValue   bool
+		release         *releasev1alpha1.Release
+		webhookEnabled  bool
+		expectedHistory []releasev1alpha1.ReleaseStatusStateTransition
 	}{
 		{
-			name: "case 0: pre-k8s 1.18 kvm-operator with matching pod",
-			pods: []runtime.Object{
-				&v1.Pod{
-					ObjectMeta: metav1.ObjectMeta{
-						Labels: map[string]string{
-							PodWatcherLabel: "kvm-operator",
-						},
-						Annotations: map[string]string{
-							KVMVersionBundleVersionAnnotation: "1.0.0",
-						},
+			name: "case 0: initial state is recorded",
+			release: &releasev1alpha1.Release{
+				Spec: releasev1alpha1.ReleaseSpec{
+					State: releasev1alpha1.StateWIP,
+				},
+			},
+			expectedHistory: []releasev1alpha1.ReleaseStatusStateTransition{
+				{To: releasev1alpha1.StateWIP, Time: now},
+			},
+		},
+		{
+			name: "case 1: unchanged state is not recorded",
+			release: &releasev1alpha1.Release{
+				Spec: releasev1alpha1.ReleaseSpec{
+					State: releasev1alpha1.StateWIP,
+				},
+				Status: releasev1alpha1.ReleaseStatus{
+					StateHistory: []releasev1alpha1.ReleaseStatusStateTransition{
+						{To: releasev1alpha1.StateWIP, Time: earlier},
 					},
 				},
 			},
-			operatorVersion: "1.0.0",
-			expectedValue:   true,
+			expectedHistory: []releasev1alpha1.ReleaseStatusStateTransition{
+				{To: releasev1alpha1.StateWIP, Time: earlier},
+			},
 		},
 		{
-			name: "case 1: k8s 1.18 kvm-operator with matching pod",
-			pods: []runtime.Object{
-				&v1.Pod{
-					ObjectMeta: metav1.ObjectMeta{
-						Labels: map[string]string{
-							PodWatcherLabel:         "kvm-operator",
-							KVMOperatorVersionLabel: "1.0.0",
-						},
+			name: "case 2: transition is recorded with the user changing the state",
+			release: &releasev1alpha1.Release{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						key.StateChangedByAnnotation: "jane",
+						key.StateChangedToAnnotation: "preview",
 					},
 				},
+				Spec: releasev1alpha1.ReleaseSpec{
+					State: releasev1alpha1.StatePreview,
+				},
+				Status: releasev1alpha1.ReleaseStatus{
+					StateHistory: []releasev1alpha1.ReleaseStatusStateTransition{
+						{To: releasev1alpha1.StateWIP, Time: earlier},
+					},
+				},
+			},
+			webhookEnabled: true,
+			expectedHistory: []releasev1alpha1.ReleaseStatusStateTransition{
+				{To: releasev1alpha1.StateWIP, Time: earlier},
+				{From: releasev1alpha1.StateWIP, To: releasev1alpha1.StatePreview, Time: now, User: "jane"},
 			},
-			operatorVersion: "1.0.0",
-			expectedValue:   true,
 		},
 		{
-			name: "case 2: pre-k8s 1.18 kvm-operator without matching pod",
-			pods: []runtime.Object{
-				&v1.Pod{
-					ObjectMeta: metav1.ObjectMeta{
-						Labels: map[string]string{
-							PodWatcherLabel: "kvm-operator",
-						},
-						Annotations: map[string]string{
-							KVMVersionBundleVersionAnnotation: "1.0.1",
-						},
+			name: "case 3: user of an outdated annotation is not recorded",
+			release: &releasev1alpha1.Release{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						key.StateChangedByAnnotation: "jane",
+						key.StateChangedToAnnotation: "preview",
+					},
+				},
+				Spec: releasev1alpha1.ReleaseSpec{
+					State: releasev1alpha1.StateActive,
+				},
+				Status: releasev1alpha1.ReleaseStatus{
+					StateHistory: []releasev1alpha1.ReleaseStatusStateTransition{
+						{To: releasev1alpha1.StatePreview, Time: earlier},
 					},
 				},
 			},
-			operatorVersion: "1.0.0",
-			expectedValue:   false,
+			webhookEnabled: true,
+			expectedHistory: []releasev1alpha1.ReleaseStatusStateTransition{
+				{To: releasev1alpha1.StatePreview, Time: earlier},
+				{From: releasev1alpha1.StatePreview, To: releasev1alpha1.StateActive, Time: now},
+			},
 		},
 		{
-			name: "case 3: k8s 1.18 kvm-operator without matching pod",
-			pods: []runtime.Object{
-				&v1.Pod{
-					ObjectMeta: metav1.ObjectMeta{
-						Labels: map[string]string{
-							PodWatcherLabel:         "kvm-operator",
-							KVMOperatorVersionLabel: "1.0.1",
-						},
+			name: "case 4: user is not recorded when the mutating webhook is disabled",
+			release: &releasev1alpha1.Release{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						key.StateChangedByAnnotation: "jane",
+						key.StateChangedToAnnotation: "preview",
 					},
 				},
+				Spec: releasev1alpha1.ReleaseSpec{
+					State: releasev1alpha1.StatePreview,
+				},
+				Status: releasev1alpha1.ReleaseStatus{
+					StateHistory: []releasev1alpha1.ReleaseStatusStateTransition{
+						{To: releasev1alpha1.StateWIP, Time: earlier},
+					},
+				},
+			},
+			expectedHistory: []releasev1alpha1.ReleaseStatusStateTransition{
+				{To: releasev1alpha1.StateWIP, Time: earlier},
+				{From: releasev1alpha1.StateWIP, To: releasev1alpha1.StatePreview, Time: now},
 			},
-			operatorVersion: "1.0.0",
-			expectedValue:   false,
 		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			result := stateHistory(tc.release, tc.release.Spec.State, tc.webhookEnabled, now)
+			if !cmp.Equal(result, tc.expectedHistory) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedHistory, result))
+			}
+		})
+	}
+}
+
+func Test_Resource_acceptedState(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	promotion := key.StatePromotion{
+		Enforce:  true,
+		SoakTime: 24 * time.Hour,
+	}
+
+	readySince := func(since time.Time) releasev1alpha1.ReleaseStatus {
+		return releasev1alpha1.ReleaseStatus{
+			Ready: true,
+			Conditions: []metav1.Condition{
+				{
+					Type:               releasev1alpha1.ConditionComponentsDeployed,
+					Status:             metav1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(since),
+				},
+			},
+		}
+	}
+	withHistory := func(status releasev1alpha1.ReleaseStatus, state releasev1alpha1.ReleaseState) releasev1alpha1.ReleaseStatus {
+		status.State = state
+		status.StateHistory = []releasev1alpha1.ReleaseStatusStateTransition{
+			{To: state, Time: metav1.NewTime(now.Add(-48 * time.Hour))},
+		}
+		return status
+	}
+
+	testCases := []struct {
+		name              string
+		promotion         key.StatePromotion
+		state             releasev1alpha1.ReleaseState
+		status            releasev1alpha1.ReleaseStatus
+		expectedState     releasev1alpha1.ReleaseState
+		expectedRejection bool
+	}{
+		{
+			name:          "case 0: new release is accepted as active without enforced promotion",
+			state:         releasev1alpha1.StateActive,
+			expectedState: releasev1alpha1.StateActive,
+		},
+		{
+			name:              "case 1: deprecated release is not moved back to wip",
+			state:             releasev1alpha1.StateWIP,
+			status:            withHistory(releasev1alpha1.ReleaseStatus{}, releasev1alpha1.StateDeprecated),
+			expectedState:     releasev1alpha1.StateDeprecated,
+			expectedRejection: true,
+		},
+		{
+			name:              "case 2: deprecated release without state history is not moved back to wip",
+			state:             releasev1alpha1.StateWIP,
+			status:            releasev1alpha1.ReleaseStatus{State: releasev1alpha1.StateDeprecated},
+			expectedState:     releasev1alpha1.StateDeprecated,
+			expectedRejection: true,
+		},
+		{
+			name:          "case 3: existing active release without state history is not demoted with enforced promotion",
+			promotion:     promotion,
+			state:         releasev1alpha1.StateActive,
+			status:        releasev1alpha1.ReleaseStatus{State: releasev1alpha1.StateActive},
+			expectedState: releasev1alpha1.StateActive,
+		},
+		{
+			name:              "case 4: preview release is kept before the soak time passed",
+			promotion:         promotion,
+			state:             releasev1alpha1.StateActive,
+			status:            withHistory(readySince(now.Add(-time.Hour)), releasev1alpha1.StatePreview),
+			expectedState:     releasev1alpha1.StatePreview,
+			expectedRejection: true,
+		},
+		{
+			name:              "case 5: preview release is kept while not ready",
+			promotion:         promotion,
+			state:             releasev1alpha1.StateActive,
+			status:            withHistory(releasev1alpha1.ReleaseStatus{}, releasev1alpha1.StatePreview),
+			expectedState:     releasev1alpha1.StatePreview,
+			expectedRejection: true,
+		},
+		{
+			name:          "case 6: preview release becomes active once the soak time passed",
+			promotion:     promotion,
+			state:         releasev1alpha1.StateActive,
+			status:        withHistory(readySince(now.Add(-25*time.Hour)), releasev1alpha1.StatePreview),
+			expectedState: releasev1alpha1.StateActive,
+		},
+		{
+			name:          "case 7: active release is deprecated",
+			promotion:     promotion,
+			state:         releasev1alpha1.StateDeprecated,
+			status:        withHistory(releasev1alpha1.ReleaseStatus{}, releasev1alpha1.StateActive),
+			expectedState: releasev1alpha1.StateDeprecated,
+		},
+		{
+			name:          "case 8: release observed for the first time seeds the history with spec.state",
+			promotion:     promotion,
+			state:         releasev1alpha1.StateActive,
+			expectedState: releasev1alpha1.StateActive,
+		},
+		{
+			name:              "case 9: later change of a release seeded as wip is validated",
+			promotion:         promotion,
+			state:             releasev1alpha1.StateActive,
+			status:            withHistory(releasev1alpha1.ReleaseStatus{}, releasev1alpha1.StateWIP),
+			expectedState:     releasev1alpha1.StateWIP,
+			expectedRejection: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			release := &releasev1alpha1.Release{
+				Spec: releasev1alpha1.ReleaseSpec{
+					State: tc.state,
+				},
+				Status: tc.status,
+			}
+
+			r := &Resource{
+				statePromotion: tc.promotion,
+			}
+
+			state, rejection := r.acceptedState(release, now)
+			if state != tc.expectedState {
+				t.Fatalf("expected state %#q got %#q", tc.expectedState, state)
+			}
+			if (rejection != "") != tc.expectedRejection {
+				t.Fatalf("expected rejection %t got %#q", tc.expectedRejection, rejection)
+			}
+		})
+	}
+}
+
+func Test_Resource_EnsureCreated_clusterDiscovery(t *testing.T) {
+	previouslyUsedBy := []releasev1alpha1.ReleaseStatusCluster{
+		{Namespace: "default", Name: "abc12", Reason: releasev1alpha1.ClusterUsageReasonReleaseLabel},
+	}
+
+	testCases := []struct {
+		name              string
+		snapshot          inventory.Snapshot
+		snapshotErr       error
+		previousInUse     bool
+		expectedInUse     bool
+		expectedInUseBy   []releasev1alpha1.ReleaseStatusCluster
+		expectedCondition metav1.ConditionStatus
+	}{
 		{
-			name:            "case 4: no pods",
-			pods:            nil,
-			operatorVersion: "1.0.0",
-			expectedValue:   false,
+			name:              "case 0: release not found in a complete snapshot is not in use",
+			snapshot:          inventory.Snapshot{Clusters: testClusters[1:]},
+			previousInUse:     true,
+			expectedInUse:     false,
+			expectedInUseBy:   nil,
+			expectedCondition: metav1.ConditionFalse,
+		},
+		{
+			name:              "case 1: release keeps being in use when discovery fails",
+			snapshotErr:       errors.New("inventory has not been synced yet"),
+			previousInUse:     true,
+			expectedInUse:     true,
+			expectedInUseBy:   previouslyUsedBy,
+			expectedCondition: metav1.ConditionTrue,
+		},
+		{
+			name: "case 2: release keeps being in use when some sources fail",
+			snapshot: inventory.Snapshot{
+				Clusters: testClusters[1:],
+				Failures: []string{"infrastructure.giantswarm.io/v1alpha3, Resource=awsclusters: connection refused"},
+			},
+			previousInUse:     true,
+			expectedInUse:     true,
+			expectedInUseBy:   previouslyUsedBy,
+			expectedCondition: metav1.ConditionTrue,
+		},
+		{
+			name: "case 3: release found by the remaining sources is in use by the found clusters",
+			snapshot: inventory.Snapshot{
+				Clusters: testClusters,
+				Failures: []string{"infrastructure.giantswarm.io/v1alpha3, Resource=awsclusters: connection refused"},
+			},
+			previousInUse: true,
+			expectedInUse: true,
+			expectedInUseBy: []releasev1alpha1.ReleaseStatusCluster{
+				{Namespace: "default", Name: "abc12", Reason: releasev1alpha1.ClusterUsageReasonReleaseLabel},
+			},
+			expectedCondition: metav1.ConditionTrue,
+		},
+		{
+			name: "case 4: release which was not in use stays unused when some sources fail",
+			snapshot: inventory.Snapshot{
+				Clusters: testClusters[1:],
+				Failures: []string{"infrastructure.giantswarm.io/v1alpha3, Resource=awsclusters: connection refused"},
+			},
+			previousInUse:     false,
+			expectedInUse:     false,
+			expectedInUseBy:   nil,
+			expectedCondition: metav1.ConditionTrue,
 		},
 	}
 
 	for i, tc := range testCases {
-		tc := tc
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			t.Log(tc.name)
 
-			fakeK8sClient := k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
-				K8sClient: fake.NewSimpleClientset(tc.pods...),
+			release := &releasev1alpha1.Release{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "v9.8.7",
+				},
+				Spec: releasev1alpha1.ReleaseSpec{
+					State: releasev1alpha1.StateDeprecated,
+				},
+			}
+			if tc.previousInUse {
+				release.Status.InUse = true
+				release.Status.InUseBy = previouslyUsedBy
+				release.Status.ClusterCount = len(previouslyUsedBy)
+			}
+
+			scheme := runtime.NewScheme()
+			for _, addToScheme := range []func(*runtime.Scheme) error{releasev1alpha1.AddToScheme, appv1alpha1.AddToScheme, corev1alpha1.AddToScheme} {
+				err := addToScheme(scheme)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			ctrlClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(release).WithStatusSubresource(&releasev1alpha1.Release{}).Build()
+
+			r, err := New(Config{
+				EventRecorder: record.NewFakeRecorder(10),
+				Inventory:     testInventory{snapshot: tc.snapshot, err: tc.snapshotErr},
+				K8sClient: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+					CtrlClient: ctrlClient,
+				}),
+				Logger: microloggertest.New(),
 			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = r.EnsureCreated(context.Background(), release)
+			if err != nil {
+				t.Fatal(err)
+			}
 
-			r := Resource{
-				k8sClient: fakeK8sClient,
+			var result releasev1alpha1.Release
+			err = ctrlClient.Get(context.Background(), client.ObjectKey{Name: release.Name}, &result)
+			if err != nil {
+				t.Fatal(err)
 			}
-			result, err := r.getKVMOperatorVersionPodsExist(context.Background(), tc.operatorVersion)
-			if !cmp.Equal(err, nil) {
-				t.Fatalf("\n\n%s\n", cmp.Diff(err, nil))
+
+			if !cmp.Equal(result.Status.InUse, tc.expectedInUse) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedInUse, result.Status.InUse))
+			}
+			if !cmp.Equal(result.Status.InUseBy, tc.expectedInUseBy) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedInUseBy, result.Status.InUseBy))
 			}
-			if !cmp.Equal(result, tc.expectedValue) {
-				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedValue, result))
+
+			condition := meta.FindStatusCondition(result.Status.Conditions, releasev1alpha1.ConditionClusterDiscoveryDegraded)
+			if condition == nil {
+				t.Fatalf("expected condition %#q to be set", releasev1alpha1.ConditionClusterDiscoveryDegraded)
+			}
+			if !cmp.Equal(condition.Status, tc.expectedCondition) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedCondition, condition.Status))
 			}
 		})
 	}
 }
+
+// testInventory is an inventory.Interface returning a fixed snapshot or error.
+type testInventory struct {
+	snapshot inventory.Snapshot
+	err      error
+}
+
+func (i testInventory) Snapshot(ctx context.Context) (inventory.Snapshot, error) {
+	return i.snapshot, i.err
+}
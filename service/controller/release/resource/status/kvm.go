@@ -1,38 +1,28 @@
 package status
 
 import (
-	"context"
-	"fmt"
-
-	"github.com/giantswarm/microerror"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	"github.com/giantswarm/release-operator/v4/service/controller/key"
+	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
 )
 
-const (
-	// from https://github.com/giantswarm/kvm-operator/blob/9dc5f0d8075731c600e2852b27e71dbc2e91015d/service/controller/key/key.go#L123
-	PodWatcherLabel = "kvm-operator.giantswarm.io/pod-watcher"
-	// from https://github.com/giantswarm/kvm-operator/blob/9dc5f0d8075731c600e2852b27e71dbc2e91015d/service/controller/key/key.go#L101
-	KVMVersionBundleVersionAnnotation = "kvm-operator.giantswarm.io/version-bundle"
-	// from https://github.com/giantswarm/kvm-operator/blob/eee64f540cae53d530628d50e54883b636d0693f/pkg/label/label.go#L17
-	KVMOperatorVersionLabel = "kvm-operator.giantswarm.io/version"
-)
+// Returns one entry per namespace of the given kvm-operator pods. With KVM
+// every cluster has its own namespace named after the cluster ID.
+func clustersWithKVMPods(pods []metav1.PartialObjectMetadata) []releasev1alpha1.ReleaseStatusCluster {
+	var result []releasev1alpha1.ReleaseStatusCluster
 
-func (r *Resource) getKVMOperatorVersionPodsExist(ctx context.Context, operatorVersion string) (bool, error) {
-	pods, err := r.k8sClient.K8sClient().CoreV1().Pods("").List(ctx, metav1.ListOptions{
-		LabelSelector: fmt.Sprintf("%s=%s", PodWatcherLabel, key.ProviderOperatorKVM),
-	})
-	if err != nil {
-		return false, microerror.Mask(err)
-	}
-
-	for _, pod := range pods.Items {
-		if pod.Annotations[KVMVersionBundleVersionAnnotation] == operatorVersion ||
-			pod.Labels[KVMOperatorVersionLabel] == operatorVersion {
-			return true, nil
+	seen := map[string]bool{}
+	for _, pod := range pods {
+		if seen[pod.Namespace] {
+			continue
 		}
+		seen[pod.Namespace] = true
+
+		result = append(result, releasev1alpha1.ReleaseStatusCluster{
+			Name:   pod.Namespace,
+			Reason: releasev1alpha1.ClusterUsageReasonKVMPods,
+		})
 	}
 
-	return false, nil
+	return result
 }
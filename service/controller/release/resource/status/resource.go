@@ -4,23 +4,62 @@ import (
 	"github.com/giantswarm/k8sclient/v7/pkg/k8sclient"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/giantswarm/release-operator/v4/service/controller/inventory"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
 )
 
 const (
 	Name = "status"
 )
 
+const (
+	// maxStateHistory is the number of state transitions kept in the status.
+	maxStateHistory = 10
+)
+
 type Config struct {
-	K8sClient k8sclient.Interface
-	Logger    micrologger.Logger
+	EventRecorder record.EventRecorder
+	Inventory     inventory.Interface
+	K8sClient     k8sclient.Interface
+	Logger        micrologger.Logger
+
+	// DeprecateEndOfLife makes the resource set spec.state of the Release to
+	// deprecated once its end of life date passed. Otherwise only
+	// status.state is.
+	DeprecateEndOfLife bool
+	// SkipConfigs makes all components being treated as if they set
+	// skipConfig, so that no Config CRs are looked up.
+	SkipConfigs bool
+	// StatePromotion configures when releases may become active. Invalid
+	// state transitions are rejected independently of the admission webhook.
+	StatePromotion key.StatePromotion
+	// WebhookEnabled is whether the mutating webhook sets the annotations
+	// recording who changed spec.state. They are only trusted for the state
+	// history then, as anyone may set them otherwise.
+	WebhookEnabled bool
 }
 
 type Resource struct {
-	k8sClient k8sclient.Interface
-	logger    micrologger.Logger
+	eventRecorder record.EventRecorder
+	inventory     inventory.Interface
+	k8sClient     k8sclient.Interface
+	logger        micrologger.Logger
+
+	deprecateEndOfLife bool
+	skipConfigs        bool
+	statePromotion     key.StatePromotion
+	webhookEnabled     bool
 }
 
 func New(config Config) (*Resource, error) {
+	if config.EventRecorder == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.EventRecorder must not be empty", config)
+	}
+	if config.Inventory == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Inventory must not be empty", config)
+	}
 	if config.K8sClient == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
 	}
@@ -29,8 +68,15 @@ func New(config Config) (*Resource, error) {
 	}
 
 	r := &Resource{
-		k8sClient: config.K8sClient,
-		logger:    config.Logger,
+		eventRecorder: config.EventRecorder,
+		inventory:     config.Inventory,
+		k8sClient:     config.K8sClient,
+		logger:        config.Logger,
+
+		deprecateEndOfLife: config.DeprecateEndOfLife,
+		skipConfigs:        config.SkipConfigs,
+		statePromotion:     config.StatePromotion,
+		webhookEnabled:     config.WebhookEnabled,
 	}
 
 	return r, nil
@@ -7,51 +7,40 @@ import (
 	"github.com/giantswarm/operatorkit/v7/pkg/resource"
 	"github.com/giantswarm/operatorkit/v7/pkg/resource/wrapper/metricsresource"
 	"github.com/giantswarm/operatorkit/v7/pkg/resource/wrapper/retryresource"
+	"k8s.io/client-go/tools/record"
 
-	"github.com/giantswarm/release-operator/v4/service/controller/release/resource/apps"
-	"github.com/giantswarm/release-operator/v4/service/controller/release/resource/configs"
+	"github.com/giantswarm/release-operator/v4/service/controller/inventory"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
 	"github.com/giantswarm/release-operator/v4/service/controller/release/resource/status"
 )
 
 type ResourceSetConfig struct {
-	K8sClient k8sclient.Interface
-	Logger    micrologger.Logger
+	EventRecorder record.EventRecorder
+	Inventory     inventory.Interface
+	K8sClient     k8sclient.Interface
+	Logger        micrologger.Logger
+
+	DeprecateEndOfLifeReleases bool
+	SkipConfigs                bool
+	StatePromotion             key.StatePromotion
+	WebhookEnabled             bool
 }
 
 func NewResourceSet(config ResourceSetConfig) ([]resource.Interface, error) {
 	var err error
 
-	var appsResource resource.Interface
-	{
-		c := apps.Config{
-			K8sClient: config.K8sClient,
-			Logger:    config.Logger,
-		}
-
-		appsResource, err = apps.New(c)
-		if err != nil {
-			return nil, microerror.Mask(err)
-		}
-	}
-
-	var configsResource resource.Interface
-	{
-		c := configs.Config{
-			K8sClient: config.K8sClient,
-			Logger:    config.Logger,
-		}
-
-		configsResource, err = configs.New(c)
-		if err != nil {
-			return nil, microerror.Mask(err)
-		}
-	}
-
 	var statusResource resource.Interface
 	{
 		c := status.Config{
-			K8sClient: config.K8sClient,
-			Logger:    config.Logger,
+			EventRecorder: config.EventRecorder,
+			Inventory:     config.Inventory,
+			K8sClient:     config.K8sClient,
+			Logger:        config.Logger,
+
+			DeprecateEndOfLife: config.DeprecateEndOfLifeReleases,
+			SkipConfigs:        config.SkipConfigs,
+			StatePromotion:     config.StatePromotion,
+			WebhookEnabled:     config.WebhookEnabled,
 		}
 
 		statusResource, err = status.New(c)
@@ -60,10 +49,10 @@ func NewResourceSet(config ResourceSetConfig) ([]resource.Interface, error) {
 		}
 	}
 
+	// Apps and Configs are managed for all releases at once by the release
+	// set controller.
 	resources := []resource.Interface{
 		statusResource,
-		configsResource,
-		appsResource,
 	}
 
 	{
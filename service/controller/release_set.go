@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	corev1alpha1 "github.com/giantswarm/config-controller/api/v1alpha1"
+	"github.com/giantswarm/k8sclient/v7/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/operatorkit/v7/pkg/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/giantswarm/release-operator/v4/api/v1alpha1"
+	"github.com/giantswarm/release-operator/v4/pkg/project"
+	"github.com/giantswarm/release-operator/v4/service/controller/controllercontext"
+	"github.com/giantswarm/release-operator/v4/service/controller/inventory"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
+	"github.com/giantswarm/release-operator/v4/service/controller/releaseset"
+)
+
+const (
+	// DefaultReleaseSetDebouncePeriod is the time events are collected for
+	// before the release set is reconciled.
+	DefaultReleaseSetDebouncePeriod = 5 * time.Second
+	// DefaultReleaseSetResyncPeriod is the duration after which the release
+	// set is reconciled even without any events.
+	DefaultReleaseSetResyncPeriod = 5 * time.Minute
+)
+
+var (
+	releaseSetControllerName = project.Name() + "-release-set"
+
+	// releaseSetRequest is the only request ever enqueued by the release set
+	// controller. All events are collapsed into it so that a burst of events
+	// results in a single reconciliation.
+	releaseSetRequest = reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name: "release-set",
+		},
+	}
+)
+
+type ReleaseSetConfig struct {
+	EventRecorder  record.EventRecorder
+	Inventory      inventory.Interface
+	K8sClient      k8sclient.Interface
+	Logger         micrologger.Logger
+	MetadataClient metadata.Interface
+
+	// CheckUserConfigSecrets makes Apps only being applied once the user
+	// values Secrets they reference exist.
+	CheckUserConfigSecrets bool
+	// ComponentDefaults are operator wide defaults for the App CRs of
+	// components.
+	ComponentDefaults key.ComponentDefaults
+	// ConfigWaitThreshold is the duration a component may wait for its Config
+	// before a warning event is emitted on its releases.
+	ConfigWaitThreshold time.Duration
+	DebouncePeriod      time.Duration
+	// DryRun makes the release set only report planned App and Config
+	// changes without applying them.
+	DryRun bool
+	// MaxAppDeletions is the number of obsolete Apps which may be deleted in a
+	// single loop. When more Apps are obsolete, none of them is deleted.
+	MaxAppDeletions int
+	// MaxReleaseDeletions is the number of unused deprecated Releases which
+	// may be deleted in a single loop. When more Releases expired, none of
+	// them is deleted.
+	MaxReleaseDeletions int
+	// ReleaseRetention is the duration a deprecated Release must have been
+	// unused before it is deleted. Releases are never deleted when it is zero.
+	ReleaseRetention time.Duration
+	ResyncPeriod     time.Duration
+	// SkipConfigs disables the creation of Config CRs for all components.
+	// Configs are not watched then.
+	SkipConfigs bool
+}
+
+// ReleaseSet reconciles the Apps and Configs of all releases at once. It is
+// triggered by any change to a Release or to an App or Config managed by
+// release-operator. Desired components are computed once per reconciliation
+// and the resulting diff is applied once instead of per Release.
+type ReleaseSet struct {
+	// client reads from the cache of the manager the release set is set up
+	// with, which the watches keep in sync.
+	client    client.Reader
+	k8sClient k8sclient.Interface
+	logger    micrologger.Logger
+	resources []resource.Interface
+
+	debouncePeriod time.Duration
+	resyncPeriod   time.Duration
+	skipConfigs    bool
+}
+
+func NewReleaseSet(config ReleaseSetConfig) (*ReleaseSet, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.DebouncePeriod == 0 {
+		config.DebouncePeriod = DefaultReleaseSetDebouncePeriod
+	}
+	if config.ResyncPeriod == 0 {
+		config.ResyncPeriod = DefaultReleaseSetResyncPeriod
+	}
+
+	var err error
+
+	var resourceSet []resource.Interface
+	{
+		c := releaseset.ResourceSetConfig{
+			EventRecorder:  config.EventRecorder,
+			Inventory:      config.Inventory,
+			K8sClient:      config.K8sClient,
+			Logger:         config.Logger,
+			MetadataClient: config.MetadataClient,
+
+			CheckUserConfigSecrets: config.CheckUserConfigSecrets,
+			ComponentDefaults:      config.ComponentDefaults,
+			ConfigWaitThreshold:    config.ConfigWaitThreshold,
+			DryRun:                 config.DryRun,
+			MaxAppDeletions:        config.MaxAppDeletions,
+			MaxReleaseDeletions:    config.MaxReleaseDeletions,
+			ReleaseRetention:       config.ReleaseRetention,
+			SkipConfigs:            config.SkipConfigs,
+		}
+
+		resourceSet, err = releaseset.NewResourceSet(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	r := &ReleaseSet{
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+		resources: resourceSet,
+
+		debouncePeriod: config.DebouncePeriod,
+		resyncPeriod:   config.ResyncPeriod,
+		skipConfigs:    config.SkipConfigs,
+	}
+
+	return r, nil
+}
+
+// SetupWithManager registers the watches of Releases, Apps and Configs with
+// the given manager.
+func (r *ReleaseSet) SetupWithManager(mgr manager.Manager) error {
+	r.client = mgr.GetClient()
+
+	managedBy := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetLabels()[key.LabelManagedBy] == project.Name()
+	})
+
+	b := builder.
+		ControllerManagedBy(mgr).
+		Named(releaseSetControllerName).
+		WithOptions(crcontroller.Options{
+			MaxConcurrentReconciles: 1,
+		}).
+		Watches(new(v1alpha1.Release), r.eventHandler()).
+		Watches(new(appv1alpha1.App), r.eventHandler(), builder.WithPredicates(managedBy))
+	if !r.skipConfigs {
+		b = b.Watches(new(corev1alpha1.Config), r.eventHandler(), builder.WithPredicates(managedBy))
+	}
+
+	err := b.Complete(r)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// Reconcile implements reconcile.Reconciler. The request is ignored since the
+// release set is always reconciled as a whole. It is requeued after the resync
+// period so that it is reconciled even without any events.
+func (r *ReleaseSet) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	var releases v1alpha1.ReleaseList
+	{
+		err := r.client.List(ctx, &releases)
+		if err != nil {
+			return reconcile.Result{}, microerror.Mask(err)
+		}
+		releases = key.ExcludeDeletedRelease(releases)
+		releases = key.ExcludeUnusedDeprecatedReleases(releases)
+	}
+
+	ctx = controllercontext.NewContext(ctx, controllercontext.Context{
+		Releases:              releases.Items,
+		Components:            key.ExtractComponents(releases),
+		ComponentDependencies: key.ExtractComponentDependencies(releases),
+		Apps:                  key.ExtractApps(releases),
+		AppDependencies:       key.ExtractAppDependencies(releases),
+	})
+
+	for _, res := range r.resources {
+		err := res.EnsureCreated(ctx, nil)
+		if err != nil {
+			r.logger.LogCtx(ctx, "level", "error", "message", fmt.Sprintf("failed to reconcile release set in resource %#q", res.Name()), "stack", microerror.JSON(err))
+			return reconcile.Result{}, microerror.Mask(err)
+		}
+	}
+
+	return reconcile.Result{RequeueAfter: r.resyncPeriod}, nil
+}
+
+// eventHandler maps every event to the single release set request. The
+// request is only added after the debounce period so that events arriving in
+// the meantime are collapsed by the work queue.
+func (r *ReleaseSet) eventHandler() handler.EventHandler {
+	enqueue := func(q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+		q.AddAfter(releaseSetRequest, r.debouncePeriod)
+	}
+
+	return handler.Funcs{
+		CreateFunc: func(_ context.Context, _ event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(q)
+		},
+		UpdateFunc: func(_ context.Context, _ event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(q)
+		},
+		DeleteFunc: func(_ context.Context, _ event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(q)
+		},
+		GenericFunc: func(_ context.Context, _ event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(q)
+		},
+	}
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/k8sclient/v7/pkg/k8sclienttest"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/v7/pkg/resource"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/giantswarm/release-operator/v4/api/v1alpha1"
+	"github.com/giantswarm/release-operator/v4/service/controller/controllercontext"
+)
+
+func Test_ReleaseSet_eventHandler(t *testing.T) {
+	r := &ReleaseSet{
+		debouncePeriod: 10 * time.Millisecond,
+	}
+
+	q := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer q.ShutDown()
+
+	h := r.eventHandler()
+	for i := 0; i < 50; i++ {
+		h.Create(context.Background(), event.CreateEvent{Object: &v1alpha1.Release{}}, q)
+		h.Update(context.Background(), event.UpdateEvent{ObjectOld: &v1alpha1.Release{}, ObjectNew: &v1alpha1.Release{}}, q)
+	}
+
+	if !cmp.Equal(q.Len(), 0) {
+		t.Fatalf("expected no request before the debounce period passed, got %d", q.Len())
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if !cmp.Equal(q.Len(), 1) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(1, q.Len()))
+	}
+}
+
+func Test_ReleaseSet_Reconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := v1alpha1.AddToScheme(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var releases []runtime.Object
+	for _, name := range []string{"v1.0.0", "v2.0.0", "v3.0.0"} {
+		releases = append(releases, &v1alpha1.Release{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Spec: v1alpha1.ReleaseSpec{
+				Components: []v1alpha1.ReleaseSpecComponent{
+					{
+						Catalog:               "control-plane-catalog",
+						Name:                  "app-operator",
+						ReleaseOperatorDeploy: true,
+						Version:               "1.0.0",
+					},
+				},
+				State: v1alpha1.StateActive,
+			},
+		})
+	}
+
+	ctrlClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(releases...).Build()
+
+	first := &testResource{name: "first"}
+	second := &testResource{name: "second"}
+
+	r := &ReleaseSet{
+		client: ctrlClient,
+		k8sClient: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+			CtrlClient: ctrlClient,
+		}),
+		logger:    microloggertest.New(),
+		resources: []resource.Interface{first, second},
+	}
+
+	_, err = r.Reconcile(context.Background(), releaseSetRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every resource is executed exactly once for all releases together.
+	for _, res := range []*testResource{first, second} {
+		if !cmp.Equal(res.calls, 1) {
+			t.Fatalf("\n\n%s\n", cmp.Diff(1, res.calls))
+		}
+		if !cmp.Equal(res.components, []string{"app-operator-1.0.0"}) {
+			t.Fatalf("\n\n%s\n", cmp.Diff([]string{"app-operator-1.0.0"}, res.components))
+		}
+	}
+}
+
+type testResource struct {
+	name       string
+	calls      int
+	components []string
+}
+
+func (r *testResource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.calls++
+	for name := range cc.Components {
+		r.components = append(r.components, name)
+	}
+
+	return nil
+}
+
+func (r *testResource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	return nil
+}
+
+func (r *testResource) Name() string {
+	return r.name
+}
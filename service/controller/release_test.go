@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"strconv"
+	"testing"
+
+	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	corev1alpha1 "github.com/giantswarm/config-controller/api/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/giantswarm/release-operator/v4/api/v1alpha1"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
+)
+
+var testReleaseApp = v1alpha1.ReleaseSpecApp{
+	Name:                  "test-app",
+	ReleaseOperatorDeploy: true,
+	Version:               "1.0.0",
+}
+
+var testReleases = []v1alpha1.Release{
+	newTestRelease("v1.0.0", "1.0.0"),
+	newTestRelease("v1.1.0", "1.0.0"),
+	func() v1alpha1.Release {
+		release := newTestRelease("v2.0.0", "2.0.0")
+		release.Spec.Apps = []v1alpha1.ReleaseSpecApp{testReleaseApp}
+		return release
+	}(),
+}
+
+func Test_releasesForApp(t *testing.T) {
+	testCases := []struct {
+		name             string
+		obj              client.Object
+		expectedRequests []reconcile.Request
+	}{
+		{
+			name:             "case 0: app used by two releases",
+			obj:              newTestApp("1.0.0"),
+			expectedRequests: []reconcile.Request{newRequest("v1.0.0"), newRequest("v1.1.0")},
+		},
+		{
+			name:             "case 1: app used by one release",
+			obj:              newTestApp("2.0.0"),
+			expectedRequests: []reconcile.Request{newRequest("v2.0.0")},
+		},
+		{
+			name:             "case 2: app not used by any release",
+			obj:              newTestApp("3.0.0"),
+			expectedRequests: nil,
+		},
+		{
+			name: "case 3: release app used by one release",
+			obj: func() client.Object {
+				app := key.ConstructReleaseApp(testReleaseApp, key.ComponentDefaults{})
+				return &app
+			}(),
+			expectedRequests: []reconcile.Request{newRequest("v2.0.0")},
+		},
+		{
+			name:             "case 4: wrong type",
+			obj:              newTestConfig("1.0.0"),
+			expectedRequests: nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			requests := releasesForApp(tc.obj, testReleases)
+			if !cmp.Equal(requests, tc.expectedRequests) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedRequests, requests))
+			}
+		})
+	}
+}
+
+func Test_releasesForConfig(t *testing.T) {
+	testCases := []struct {
+		name             string
+		obj              client.Object
+		expectedRequests []reconcile.Request
+	}{
+		{
+			name:             "case 0: config used by two releases",
+			obj:              newTestConfig("1.0.0"),
+			expectedRequests: []reconcile.Request{newRequest("v1.0.0"), newRequest("v1.1.0")},
+		},
+		{
+			name:             "case 1: config not used by any release",
+			obj:              newTestConfig("3.0.0"),
+			expectedRequests: nil,
+		},
+		{
+			name: "case 2: config not managed by release-operator",
+			obj: func() client.Object {
+				c := newTestConfig("1.0.0")
+				c.Labels = nil
+				return c
+			}(),
+			expectedRequests: nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			requests := releasesForConfig(tc.obj, testReleases)
+			if !cmp.Equal(requests, tc.expectedRequests) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedRequests, requests))
+			}
+		})
+	}
+}
+
+func newTestRelease(name, appOperatorVersion string) v1alpha1.Release {
+	return v1alpha1.Release{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: v1alpha1.ReleaseSpec{
+			Components: []v1alpha1.ReleaseSpecComponent{
+				testComponent(appOperatorVersion),
+				{
+					Name:    "kubernetes",
+					Version: "1.18.0",
+				},
+			},
+		},
+	}
+}
+
+func newTestApp(version string) *appv1alpha1.App {
+	app := key.ConstructApp(testComponent(version))
+	return &app
+}
+
+func newTestConfig(version string) *corev1alpha1.Config {
+	config := key.ConstructConfig(testComponent(version))
+	return &config
+}
+
+func newRequest(name string) reconcile.Request {
+	return reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name: name,
+		},
+	}
+}
+
+func testComponent(version string) v1alpha1.ReleaseSpecComponent {
+	return v1alpha1.ReleaseSpecComponent{
+		Catalog:               "control-plane-catalog",
+		Name:                  "app-operator",
+		ReleaseOperatorDeploy: true,
+		Version:               version,
+	}
+}
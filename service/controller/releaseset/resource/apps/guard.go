@@ -0,0 +1,99 @@
+package apps
+
+import (
+	"fmt"
+	"strings"
+
+	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+
+	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
+	"github.com/giantswarm/release-operator/v4/service/controller/inventory"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
+)
+
+const (
+	// heldBackReasonInUse means the App deploys an operator version which is
+	// still used by at least one cluster.
+	heldBackReasonInUse = "InUse"
+	// heldBackReasonLimit means more Apps would have been deleted in a
+	// single loop than allowed.
+	heldBackReasonLimit = "DeletionLimit"
+	// heldBackReasonDiscoveryDegraded means clusters could not be fully
+	// discovered, so it is unknown whether the App is still used.
+	heldBackReasonDiscoveryDegraded = "ClusterDiscoveryDegraded"
+)
+
+type heldBackApp struct {
+	App     appv1alpha1.App
+	Reason  string
+	Message string
+}
+
+// guardDeletions splits the given obsolete Apps into the ones which can be
+// deleted safely and the ones which are held back. Apps deploying an operator
+// version still used by a cluster are never deleted. When more than
+// maxDeletions Apps remain, all of them are held back since this usually
+// points to a mistakenly deleted release or an incomplete list of releases.
+// All Apps are held back while the cluster discovery is degraded.
+func guardDeletions(obsoleteApps []appv1alpha1.App, snapshot inventory.Snapshot, maxDeletions int) ([]appv1alpha1.App, []heldBackApp) {
+	var deletable []appv1alpha1.App
+	var heldBack []heldBackApp
+
+	if snapshot.Degraded() {
+		for _, app := range obsoleteApps {
+			heldBack = append(heldBack, heldBackApp{
+				App:     app,
+				Reason:  heldBackReasonDiscoveryDegraded,
+				Message: fmt.Sprintf("App %#q is not deleted because clusters could not be fully discovered: %s", app.Name, strings.Join(snapshot.Failures, ", ")),
+			})
+		}
+
+		return nil, heldBack
+	}
+
+	for _, app := range obsoleteApps {
+		cluster, ok := clusterUsingApp(app, snapshot.Clusters)
+		if ok {
+			heldBack = append(heldBack, heldBackApp{
+				App:     app,
+				Reason:  heldBackReasonInUse,
+				Message: fmt.Sprintf("App %#q is not deleted because cluster %s/%s still uses %s %s", app.Name, cluster.Namespace, cluster.ID, cluster.ProviderOperator, cluster.OperatorVersion),
+			})
+			continue
+		}
+
+		deletable = append(deletable, app)
+	}
+
+	if len(deletable) > maxDeletions {
+		for _, app := range deletable {
+			heldBack = append(heldBack, heldBackApp{
+				App:     app,
+				Reason:  heldBackReasonLimit,
+				Message: fmt.Sprintf("App %#q is not deleted because %d Apps would be deleted at once, which exceeds the limit of %d", app.Name, len(deletable), maxDeletions),
+			})
+		}
+
+		return nil, heldBack
+	}
+
+	return deletable, heldBack
+}
+
+func clusterUsingApp(app appv1alpha1.App, clusters []inventory.Cluster) (inventory.Cluster, bool) {
+	for _, c := range clusters {
+		if c.ProviderOperator == "" || c.OperatorVersion == "" {
+			continue
+		}
+
+		component := releasev1alpha1.ReleaseSpecComponent{
+			Name:    c.ProviderOperator,
+			Version: c.OperatorVersion,
+		}
+		if app.Spec.Name == c.ProviderOperator && app.Name == key.BuildAppName(component) {
+			return c, true
+		}
+	}
+
+	return inventory.Cluster{}, false
+}
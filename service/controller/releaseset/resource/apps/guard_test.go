@@ -0,0 +1,161 @@
+package apps
+
+import (
+	"strconv"
+	"testing"
+
+	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/giantswarm/release-operator/v4/service/controller/inventory"
+)
+
+func Test_guardDeletions(t *testing.T) {
+	testCases := []struct {
+		name              string
+		obsoleteApps      []appv1alpha1.App
+		clusters          []inventory.Cluster
+		failures          []string
+		maxDeletions      int
+		expectedDeletable []string
+		expectedHeldBack  map[string]string
+	}{
+		{
+			name: "case 0: obsolete apps within the limit are deleted",
+			obsoleteApps: []appv1alpha1.App{
+				appForComponent(testComponents[0]),
+				appForComponent(testComponents[1]),
+			},
+			maxDeletions:      2,
+			expectedDeletable: []string{"test-1.0.0", "abc-123.0.0"},
+			expectedHeldBack:  map[string]string{},
+		},
+		{
+			name: "case 1: app used by a cluster is held back",
+			obsoleteApps: []appv1alpha1.App{
+				appForComponent(testComponents[0]),
+				appForComponent(testComponents[1]),
+			},
+			clusters: []inventory.Cluster{
+				{
+					ID:               "abc12",
+					Namespace:        "org-test",
+					OperatorVersion:  "123.0.0",
+					ProviderOperator: "abc",
+				},
+			},
+			maxDeletions:      5,
+			expectedDeletable: []string{"test-1.0.0"},
+			expectedHeldBack: map[string]string{
+				"abc-123.0.0": heldBackReasonInUse,
+			},
+		},
+		{
+			name: "case 2: all apps are held back when exceeding the limit",
+			obsoleteApps: []appv1alpha1.App{
+				appForComponent(testComponents[0]),
+				appForComponent(testComponents[1]),
+				appForComponent(testComponents[2]),
+			},
+			maxDeletions:      2,
+			expectedDeletable: nil,
+			expectedHeldBack: map[string]string{
+				"test-1.0.0":  heldBackReasonLimit,
+				"abc-123.0.0": heldBackReasonLimit,
+				"other-2.0.0": heldBackReasonLimit,
+			},
+		},
+		{
+			name: "case 3: apps in use do not count towards the limit",
+			obsoleteApps: []appv1alpha1.App{
+				appForComponent(testComponents[0]),
+				appForComponent(testComponents[1]),
+				appForComponent(testComponents[2]),
+			},
+			clusters: []inventory.Cluster{
+				{
+					ID:               "abc12",
+					Namespace:        "org-test",
+					OperatorVersion:  "2.0.0",
+					ProviderOperator: "other",
+				},
+			},
+			maxDeletions:      2,
+			expectedDeletable: []string{"test-1.0.0", "abc-123.0.0"},
+			expectedHeldBack: map[string]string{
+				"other-2.0.0": heldBackReasonInUse,
+			},
+		},
+		{
+			name: "case 4: cluster using a different version does not hold back the app",
+			obsoleteApps: []appv1alpha1.App{
+				appForComponent(testComponents[2]),
+			},
+			clusters: []inventory.Cluster{
+				{
+					ID:               "abc12",
+					Namespace:        "org-test",
+					OperatorVersion:  "3.0.0",
+					ProviderOperator: "other",
+				},
+			},
+			maxDeletions:      5,
+			expectedDeletable: []string{"other-2.0.0"},
+			expectedHeldBack:  map[string]string{},
+		},
+		{
+			name: "case 5: all apps are held back when some cluster sources fail",
+			obsoleteApps: []appv1alpha1.App{
+				appForComponent(testComponents[0]),
+				appForComponent(testComponents[1]),
+			},
+			failures:          []string{"infrastructure.giantswarm.io/v1alpha3, Resource=awsclusters: connection refused"},
+			maxDeletions:      5,
+			expectedDeletable: nil,
+			expectedHeldBack: map[string]string{
+				"test-1.0.0":  heldBackReasonDiscoveryDegraded,
+				"abc-123.0.0": heldBackReasonDiscoveryDegraded,
+			},
+		},
+		{
+			name: "case 6: all apps are held back when the maximum is zero",
+			obsoleteApps: []appv1alpha1.App{
+				appForComponent(testComponents[0]),
+			},
+			maxDeletions:      0,
+			expectedDeletable: nil,
+			expectedHeldBack: map[string]string{
+				"test-1.0.0": heldBackReasonLimit,
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			snapshot := inventory.Snapshot{
+				Clusters: tc.clusters,
+				Failures: tc.failures,
+			}
+
+			deletable, heldBack := guardDeletions(tc.obsoleteApps, snapshot, tc.maxDeletions)
+
+			var deletableNames []string
+			for _, app := range deletable {
+				deletableNames = append(deletableNames, app.Name)
+			}
+			if !cmp.Equal(deletableNames, tc.expectedDeletable) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedDeletable, deletableNames))
+			}
+
+			heldBackReasons := map[string]string{}
+			for _, h := range heldBack {
+				heldBackReasons[h.App.Name] = h.Reason
+			}
+			if !cmp.Equal(heldBackReasons, tc.expectedHeldBack) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedHeldBack, heldBackReasons))
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package apps
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	namespace = "release_operator"
+)
+
+const (
+	labelAction = "action"
+	labelApp    = "app"
+	labelReason = "reason"
+)
+
+const (
+	actionCreate = "create"
+	actionDelete = "delete"
+	actionUpdate = "update"
+)
+
+var (
+	plannedAppsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "dry_run",
+			Name:      "planned_apps",
+			Help:      "Number of Apps which would be created, updated or deleted if dry-run mode was disabled.",
+		},
+		[]string{labelAction},
+	)
+	heldBackAppsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "apps",
+			Name:      "deletions_held_back",
+			Help:      "Number of obsolete Apps which were not deleted by the deletion guard in the last loop.",
+		},
+		[]string{labelReason},
+	)
+	configWaitGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "component",
+			Name:      "config_wait_seconds",
+			Help:      "Seconds the App of a component has been waiting for its Config to be generated.",
+		},
+		[]string{labelApp},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(plannedAppsGauge)
+	prometheus.MustRegister(heldBackAppsGauge)
+	prometheus.MustRegister(configWaitGauge)
+}
@@ -0,0 +1,735 @@
+package apps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	corev1alpha1 "github.com/giantswarm/config-controller/api/v1alpha1"
+	"github.com/giantswarm/k8sclient/v7/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
+	"github.com/giantswarm/release-operator/v4/pkg/project"
+	"github.com/giantswarm/release-operator/v4/service/controller/controllercontext"
+	"github.com/giantswarm/release-operator/v4/service/controller/inventory"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
+)
+
+const (
+	Name = "apps"
+
+	// fieldManager is the field manager used when applying Apps.
+	fieldManager = "release-operator"
+)
+
+const (
+	// DefaultConfigWaitThreshold is the default duration an App may wait for
+	// its Config before a warning event is emitted.
+	DefaultConfigWaitThreshold = 10 * time.Minute
+	// DefaultMaxDeletions is the number of Apps which may be deleted in a
+	// single loop when Config.MaxDeletions is negative.
+	DefaultMaxDeletions = 5
+)
+
+type Config struct {
+	EventRecorder  record.EventRecorder
+	Inventory      inventory.Interface
+	K8sClient      k8sclient.Interface
+	Logger         micrologger.Logger
+	MetadataClient metadata.Interface
+
+	// CheckUserConfigSecrets makes Apps only being applied once the user
+	// values Secrets they reference exist. It requires get permissions on
+	// Secrets in all namespaces. User values ConfigMaps are always checked.
+	CheckUserConfigSecrets bool
+	// ComponentDefaults are applied to all components not setting the
+	// respective fields themselves.
+	ComponentDefaults key.ComponentDefaults
+	// ConfigWaitThreshold is the duration an App may wait for its Config
+	// before a warning event is emitted on the releases containing its
+	// component. Defaults to DefaultConfigWaitThreshold.
+	ConfigWaitThreshold time.Duration
+	// DryRun makes the resource only report planned changes as logs, events
+	// and metrics without applying them.
+	DryRun bool
+	// MaxDeletions is the number of obsolete Apps which may be deleted in a
+	// single loop. When more Apps are obsolete, none of them is deleted, so
+	// zero disables App deletions. Negative values use DefaultMaxDeletions.
+	MaxDeletions int
+	// SkipConfigs makes all Apps being created without waiting for a Config,
+	// like for components setting skipConfig themselves.
+	SkipConfigs bool
+}
+
+type Resource struct {
+	eventRecorder  record.EventRecorder
+	inventory      inventory.Interface
+	k8sClient      k8sclient.Interface
+	logger         micrologger.Logger
+	metadataClient metadata.Interface
+
+	checkUserConfigSecrets bool
+	componentDefaults      key.ComponentDefaults
+	configWaitThreshold    time.Duration
+	dryRun                 bool
+	maxDeletions           int
+	skipConfigs            bool
+
+	mutex sync.Mutex
+	// skipped holds why each App was skipped in the previous loop, so that
+	// AppSkipped events are only emitted when it changes.
+	skipped map[string]string
+}
+
+func New(config Config) (*Resource, error) {
+	if config.EventRecorder == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.EventRecorder must not be empty", config)
+	}
+	if config.Inventory == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Inventory must not be empty", config)
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.MetadataClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.MetadataClient must not be empty", config)
+	}
+
+	if config.ConfigWaitThreshold == 0 {
+		config.ConfigWaitThreshold = DefaultConfigWaitThreshold
+	}
+	if config.MaxDeletions < 0 {
+		config.MaxDeletions = DefaultMaxDeletions
+	}
+
+	r := &Resource{
+		eventRecorder:  config.EventRecorder,
+		inventory:      config.Inventory,
+		k8sClient:      config.K8sClient,
+		logger:         config.Logger,
+		metadataClient: config.MetadataClient,
+
+		checkUserConfigSecrets: config.CheckUserConfigSecrets,
+		componentDefaults:      config.ComponentDefaults,
+		configWaitThreshold:    config.ConfigWaitThreshold,
+		dryRun:                 config.DryRun,
+		maxDeletions:           config.MaxDeletions,
+		skipConfigs:            config.SkipConfigs,
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
+
+func (r *Resource) ensureState(ctx context.Context) error {
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	components := map[string]releasev1alpha1.ReleaseSpecComponent{}
+	for name, component := range cc.Components {
+		component = key.WithComponentDefaults(component, r.componentDefaults)
+		if r.skipConfigs {
+			component.SkipConfig = true
+		}
+		components[name] = component
+	}
+	releaseApps := cc.Apps
+
+	var apps appv1alpha1.AppList
+	{
+		err := r.k8sClient.CtrlClient().List(
+			ctx,
+			&apps,
+			&client.ListOptions{
+				LabelSelector: labels.SelectorFromSet(labels.Set{
+					key.LabelManagedBy: project.Name(),
+				}),
+			},
+		)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	// Configs are not listed when their generation is skipped, as the Config
+	// CRD may not even be installed then.
+	var configs corev1alpha1.ConfigList
+	if !r.skipConfigs {
+		err := r.k8sClient.CtrlClient().List(
+			ctx,
+			&configs,
+			&client.ListOptions{
+				LabelSelector: labels.SelectorFromSet(labels.Set{
+					key.LabelManagedBy: project.Name(),
+				}),
+			},
+		)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	var appsToDelete appv1alpha1.AppList
+	{
+		snapshot, err := r.inventory.Snapshot(ctx)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		var heldBack []heldBackApp
+		appsToDelete.Items, heldBack = guardDeletions(calculateObsoleteApps(components, releaseApps, apps).Items, snapshot, r.maxDeletions)
+		r.reportHeldBackDeletions(ctx, heldBack, cc.Releases)
+	}
+
+	// Components and release apps are only created once all apps they depend
+	// on are deployed. Apps depending on each other in a cycle are never
+	// created.
+	var appsToCreate appv1alpha1.AppList
+	skipped := map[string]string{}
+	configWaitGauge.Reset()
+	componentCycles := key.DependencyCycles(cc.ComponentDependencies)
+	for _, app := range calculateMissingApps(components, apps).Items {
+		if !r.dependenciesDeployed(ctx, app, cc.ComponentDependencies[app.Name], componentCycles, apps, cc.Releases, skipped) {
+			continue
+		}
+		if !components[app.Name].SkipConfig && !withAppConfig(&app, configs) {
+			r.reportConfigWait(ctx, app, configs, cc.Releases, skipped)
+			continue
+		}
+
+		ok, err := r.userConfigExists(ctx, app, cc.Releases)
+		if err != nil {
+			return microerror.Mask(err)
+		} else if !ok {
+			continue
+		}
+
+		appsToCreate.Items = append(appsToCreate.Items, app)
+	}
+
+	appCycles := key.DependencyCycles(cc.AppDependencies)
+	for _, app := range calculateMissingReleaseApps(releaseApps, apps, r.componentDefaults).Items {
+		if !r.dependenciesDeployed(ctx, app, cc.AppDependencies[app.Name], appCycles, apps, cc.Releases, skipped) {
+			continue
+		}
+
+		appsToCreate.Items = append(appsToCreate.Items, app)
+	}
+
+	r.mutex.Lock()
+	r.skipped = skipped
+	r.mutex.Unlock()
+
+	var appsToUpdate appv1alpha1.AppList
+	for _, app := range calculateDriftedApps(components, apps, configs).Items {
+		ok, err := r.userConfigExists(ctx, app, cc.Releases)
+		if err != nil {
+			return microerror.Mask(err)
+		} else if !ok {
+			continue
+		}
+
+		appsToUpdate.Items = append(appsToUpdate.Items, app)
+	}
+	appsToUpdate.Items = append(appsToUpdate.Items, calculateDriftedReleaseApps(releaseApps, apps, r.componentDefaults).Items...)
+
+	if r.dryRun {
+		r.reportPlannedChanges(ctx, appsToCreate, appsToUpdate, appsToDelete, cc.Releases)
+		return nil
+	}
+
+	for i, app := range appsToDelete.Items {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleting app %#q in namespace %#q", app.Name, app.Namespace))
+
+		err := r.k8sClient.CtrlClient().Delete(
+			ctx,
+			&appsToDelete.Items[i],
+		)
+		if apierrors.IsNotFound(err) {
+			// fall through.
+		} else if err != nil {
+			r.recordEvent(cc.Releases, &appsToDelete.Items[i], corev1.EventTypeWarning, "AppDeleteFailed", "Failed to delete App %#q in namespace %#q: %s", app.Name, app.Namespace, err)
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleted app %#q in namespace %#q", app.Name, app.Namespace))
+		r.recordEvent(cc.Releases, &appsToDelete.Items[i], corev1.EventTypeNormal, "AppDeleted", "Deleted App %#q in namespace %#q", app.Name, app.Namespace)
+	}
+
+	for i, app := range appsToCreate.Items {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("creating app %#q in namespace %#q", app.Name, app.Namespace))
+
+		err := r.apply(ctx, &appsToCreate.Items[i])
+		if err != nil {
+			r.recordEvent(cc.Releases, &appsToCreate.Items[i], corev1.EventTypeWarning, "AppCreateFailed", "Failed to create App %#q in namespace %#q: %s", app.Name, app.Namespace, err)
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("created app %#q in namespace %#q", app.Name, app.Namespace))
+		r.recordEvent(cc.Releases, &appsToCreate.Items[i], corev1.EventTypeNormal, "AppCreated", "Created App %#q in namespace %#q", app.Name, app.Namespace)
+	}
+
+	for i, app := range appsToUpdate.Items {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("updating drifted app %#q in namespace %#q", app.Name, app.Namespace))
+
+		err := r.apply(ctx, &appsToUpdate.Items[i])
+		if err != nil {
+			r.recordEvent(cc.Releases, &appsToUpdate.Items[i], corev1.EventTypeWarning, "AppUpdateFailed", "Failed to update App %#q in namespace %#q: %s", app.Name, app.Namespace, err)
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("updated drifted app %#q in namespace %#q", app.Name, app.Namespace))
+		r.recordEvent(cc.Releases, &appsToUpdate.Items[i], corev1.EventTypeNormal, "AppUpdated", "Updated drifted App %#q in namespace %#q", app.Name, app.Namespace)
+
+		// config-controller may regenerate the configuration of a component
+		// into differently named ConfigMaps and Secrets. Make this visible
+		// on the releases since it changes the values app-operator deploys.
+		current, _ := findApp(app.Name, apps)
+		if current.Spec.Config != app.Spec.Config {
+			r.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("updated config refs of app %#q from %s to %s", app.Name, formatAppConfig(current.Spec.Config), formatAppConfig(app.Spec.Config)))
+			r.recordEvent(cc.Releases, &appsToUpdate.Items[i], corev1.EventTypeNormal, "ConfigUpdated", "App %#q config changed from %s to %s", app.Name, formatAppConfig(current.Spec.Config), formatAppConfig(app.Spec.Config))
+		}
+	}
+
+	return nil
+}
+
+// dependenciesDeployed checks whether the given App can be created with
+// respect to its dependencies and logs why it is skipped otherwise.
+func (r *Resource) dependenciesDeployed(ctx context.Context, app appv1alpha1.App, dependencies []string, cycles []string, apps appv1alpha1.AppList, releases []releasev1alpha1.Release, skipped map[string]string) bool {
+	if containsString(cycles, app.Name) {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("skipping app %#q as it is part of a dependency cycle", app.Name))
+		r.recordSkipped(releases, &app, skipped, corev1.EventTypeWarning, fmt.Sprintf("App %#q is not created as it is part of a dependency cycle", app.Name))
+		return false
+	}
+
+	waitingFor := key.UndeployedDependencies(dependencies, apps.Items)
+	if len(waitingFor) > 0 {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("skipping app %#q as it is waiting for apps %s to be deployed", app.Name, strings.Join(waitingFor, ", ")))
+		r.recordSkipped(releases, &app, skipped, corev1.EventTypeNormal, fmt.Sprintf("App %#q is not created until apps %s are deployed", app.Name, strings.Join(waitingFor, ", ")))
+		return false
+	}
+
+	return true
+}
+
+// userConfigExists checks whether the user values ConfigMap and Secret
+// referenced by the given App exist. Only their metadata is fetched, so that
+// the values are never read. Missing objects are reported as logs and warning
+// events so that the App is not pointed to values app-operator cannot find.
+func (r *Resource) userConfigExists(ctx context.Context, app appv1alpha1.App, releases []releasev1alpha1.Release) (bool, error) {
+	type userConfig struct {
+		kind      string
+		resource  schema.GroupVersionResource
+		name      string
+		namespace string
+	}
+
+	var refs []userConfig
+	if app.Spec.UserConfig.ConfigMap.Name != "" {
+		refs = append(refs, userConfig{
+			kind:      "ConfigMap",
+			resource:  corev1.SchemeGroupVersion.WithResource("configmaps"),
+			name:      app.Spec.UserConfig.ConfigMap.Name,
+			namespace: app.Spec.UserConfig.ConfigMap.Namespace,
+		})
+	}
+	if app.Spec.UserConfig.Secret.Name != "" && r.checkUserConfigSecrets {
+		refs = append(refs, userConfig{
+			kind:      "Secret",
+			resource:  corev1.SchemeGroupVersion.WithResource("secrets"),
+			name:      app.Spec.UserConfig.Secret.Name,
+			namespace: app.Spec.UserConfig.Secret.Namespace,
+		})
+	}
+
+	for _, ref := range refs {
+		_, err := r.metadataClient.Resource(ref.resource).Namespace(ref.namespace).Get(ctx, ref.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			message := fmt.Sprintf("App %#q is not applied because user values %s %s/%s does not exist", app.Name, ref.kind, ref.namespace, ref.name)
+			r.logger.LogCtx(ctx, "level", "warning", "message", message)
+			r.recordEvent(releases, &app, corev1.EventTypeWarning, "UserConfigNotFound", "%s", message)
+			return false, nil
+		} else if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
+
+	return true, nil
+}
+
+// apply creates or updates the given App using server-side apply. Only the
+// fields set by release-operator are owned by it, so fields managed by other
+// controllers are left untouched while manual edits of owned fields are
+// reverted.
+func (r *Resource) apply(ctx context.Context, app *appv1alpha1.App) error {
+	app.TypeMeta = metav1.TypeMeta{
+		APIVersion: appv1alpha1.SchemeGroupVersion.String(),
+		Kind:       "App",
+	}
+	app.ResourceVersion = ""
+	app.ManagedFields = nil
+
+	err := r.k8sClient.CtrlClient().Patch(ctx, app, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// reportConfigWait logs and exposes as metric how long the given App has been
+// waiting for its Config. Once it waited longer than the configured threshold,
+// a warning event is emitted on every release containing its component.
+func (r *Resource) reportConfigWait(ctx context.Context, app appv1alpha1.App, configs corev1alpha1.ConfigList, releases []releasev1alpha1.Release, skipped map[string]string) {
+	since, ok := configWaitSince(app, configs)
+	if !ok {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("skipping app %#q as its config does not exist yet", app.Name))
+		r.recordSkipped(releases, &app, skipped, corev1.EventTypeNormal, fmt.Sprintf("App %#q is not created until its config is generated", app.Name))
+		return
+	}
+
+	wait := time.Since(since).Round(time.Second)
+	configWaitGauge.WithLabelValues(app.Name).Set(wait.Seconds())
+
+	if wait < r.configWaitThreshold {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("skipping app %#q as its config is not ready for %s", app.Name, wait))
+		r.recordSkipped(releases, &app, skipped, corev1.EventTypeNormal, fmt.Sprintf("App %#q is not created until its config is generated", app.Name))
+		return
+	}
+
+	message := fmt.Sprintf("App %#q has been waiting for config %#q since %s", app.Name, app.Name, since.UTC().Format(time.RFC3339))
+	r.logger.LogCtx(ctx, "level", "warning", "message", message)
+	r.recordEvent(releases, &app, corev1.EventTypeWarning, "ConfigNotReady", "%s", message)
+}
+
+// reportHeldBackDeletions logs and emits warning events for obsolete Apps
+// which are not deleted, and exposes their number per reason as metrics.
+func (r *Resource) reportHeldBackDeletions(ctx context.Context, heldBack []heldBackApp, releases []releasev1alpha1.Release) {
+	counts := map[string]int{
+		heldBackReasonInUse:             0,
+		heldBackReasonLimit:             0,
+		heldBackReasonDiscoveryDegraded: 0,
+	}
+
+	for i, h := range heldBack {
+		r.logger.LogCtx(ctx, "level", "warning", "message", h.Message)
+		r.recordEvent(releases, &heldBack[i].App, corev1.EventTypeWarning, "DeletionHeldBack", "%s", h.Message)
+		counts[h.Reason]++
+	}
+
+	for reason, count := range counts {
+		heldBackAppsGauge.WithLabelValues(reason).Set(float64(count))
+	}
+}
+
+// recordEvent emits an event on every release deploying the given App so that
+// changes can be audited per release. Apps no release deploys anymore, like
+// obsolete Apps being deleted, get the event themselves.
+func (r *Resource) recordEvent(releases []releasev1alpha1.Release, app *appv1alpha1.App, eventtype, reason, messageFmt string, args ...interface{}) {
+	deploying := key.ReleasesDeployingApp(releases, app.Name)
+	if len(deploying) == 0 {
+		r.eventRecorder.Eventf(app, eventtype, reason, messageFmt, args...)
+		return
+	}
+
+	for i := range deploying {
+		r.eventRecorder.Eventf(&deploying[i], eventtype, reason, messageFmt, args...)
+	}
+}
+
+// recordSkipped records why the given App is skipped in the current loop. An
+// AppSkipped event is only emitted when the App was not skipped for the same
+// reason in the previous loop, so that Apps waiting for a long time do not
+// flood the events of their releases.
+func (r *Resource) recordSkipped(releases []releasev1alpha1.Release, app *appv1alpha1.App, skipped map[string]string, eventtype, message string) {
+	skipped[app.Name] = message
+
+	r.mutex.Lock()
+	previous, ok := r.skipped[app.Name]
+	r.mutex.Unlock()
+	if ok && previous == message {
+		return
+	}
+
+	r.recordEvent(releases, app, eventtype, "AppSkipped", "%s", message)
+}
+
+// reportPlannedChanges logs and emits events for the given Apps instead of
+// creating or deleting them, and exposes their number as metrics. Like for
+// applied changes, events are recorded on the releases deploying the Apps
+// since Apps which would be created do not exist.
+func (r *Resource) reportPlannedChanges(ctx context.Context, appsToCreate, appsToUpdate, appsToDelete appv1alpha1.AppList, releases []releasev1alpha1.Release) {
+	for i, app := range appsToDelete.Items {
+		r.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("dry-run: would delete app %#q in namespace %#q", app.Name, app.Namespace))
+		r.recordEvent(releases, &appsToDelete.Items[i], corev1.EventTypeNormal, "DryRunDelete", "App %#q would be deleted", app.Name)
+	}
+
+	for i, app := range appsToCreate.Items {
+		r.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("dry-run: would create app %#q in namespace %#q", app.Name, app.Namespace))
+		r.recordEvent(releases, &appsToCreate.Items[i], corev1.EventTypeNormal, "DryRunCreate", "App %#q would be created", app.Name)
+	}
+
+	for i, app := range appsToUpdate.Items {
+		r.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("dry-run: would update drifted app %#q in namespace %#q", app.Name, app.Namespace))
+		r.recordEvent(releases, &appsToUpdate.Items[i], corev1.EventTypeNormal, "DryRunUpdate", "App %#q would be updated", app.Name)
+	}
+
+	plannedAppsGauge.WithLabelValues(actionCreate).Set(float64(len(appsToCreate.Items)))
+	plannedAppsGauge.WithLabelValues(actionUpdate).Set(float64(len(appsToUpdate.Items)))
+	plannedAppsGauge.WithLabelValues(actionDelete).Set(float64(len(appsToDelete.Items)))
+}
+
+func calculateMissingApps(components map[string]releasev1alpha1.ReleaseSpecComponent, apps appv1alpha1.AppList) appv1alpha1.AppList {
+	var missingApps appv1alpha1.AppList
+
+	for name, component := range components {
+		if _, ok := findApp(name, apps); !ok {
+			missingApp := key.ConstructApp(component)
+			missingApps.Items = append(missingApps.Items, missingApp)
+		}
+	}
+
+	return missingApps
+}
+
+// calculateDriftedApps returns the desired state of all existing Apps which
+// differ from it in any field owned by release-operator.
+func calculateDriftedApps(components map[string]releasev1alpha1.ReleaseSpecComponent, apps appv1alpha1.AppList, configs corev1alpha1.ConfigList) appv1alpha1.AppList {
+	var driftedApps appv1alpha1.AppList
+
+	for name, component := range components {
+		current, ok := findApp(name, apps)
+		if !ok {
+			continue
+		}
+
+		desired := key.ConstructApp(component)
+		if component.SkipConfig {
+			// Apps of components skipping Config generation have no config
+			// refs.
+		} else if !withAppConfig(&desired, configs) {
+			// Keep the current config refs as long as the Config for the
+			// desired state is not ready yet.
+			desired.Spec.Config = current.Spec.Config
+		}
+
+		if appDrifted(desired, current) {
+			driftedApps.Items = append(driftedApps.Items, desired)
+		}
+	}
+
+	sort.Slice(driftedApps.Items, func(i, j int) bool {
+		return driftedApps.Items[i].Name < driftedApps.Items[j].Name
+	})
+
+	return driftedApps
+}
+
+func calculateMissingReleaseApps(releaseApps map[string]releasev1alpha1.ReleaseSpecApp, apps appv1alpha1.AppList, defaults key.ComponentDefaults) appv1alpha1.AppList {
+	var missingApps appv1alpha1.AppList
+
+	for name, releaseApp := range releaseApps {
+		if _, ok := findApp(name, apps); !ok {
+			missingApps.Items = append(missingApps.Items, key.ConstructReleaseApp(releaseApp, defaults))
+		}
+	}
+
+	sort.Slice(missingApps.Items, func(i, j int) bool {
+		return missingApps.Items[i].Name < missingApps.Items[j].Name
+	})
+
+	return missingApps
+}
+
+// calculateDriftedReleaseApps returns the desired state of all existing Apps
+// of release apps which differ from it in any field owned by
+// release-operator.
+func calculateDriftedReleaseApps(releaseApps map[string]releasev1alpha1.ReleaseSpecApp, apps appv1alpha1.AppList, defaults key.ComponentDefaults) appv1alpha1.AppList {
+	var driftedApps appv1alpha1.AppList
+
+	for name, releaseApp := range releaseApps {
+		current, ok := findApp(name, apps)
+		if !ok {
+			continue
+		}
+
+		desired := key.ConstructReleaseApp(releaseApp, defaults)
+		if appDrifted(desired, current) {
+			driftedApps.Items = append(driftedApps.Items, desired)
+		}
+	}
+
+	sort.Slice(driftedApps.Items, func(i, j int) bool {
+		return driftedApps.Items[i].Name < driftedApps.Items[j].Name
+	})
+
+	return driftedApps
+}
+
+func calculateObsoleteApps(components map[string]releasev1alpha1.ReleaseSpecComponent, releaseApps map[string]releasev1alpha1.ReleaseSpecApp, apps appv1alpha1.AppList) appv1alpha1.AppList {
+	var obsoleteApps appv1alpha1.AppList
+
+	for _, app := range apps.Items {
+		_, isComponent := components[app.Name]
+		_, isReleaseApp := releaseApps[app.Name]
+		if !isComponent && !isReleaseApp {
+			obsoleteApps.Items = append(obsoleteApps.Items, app)
+		}
+	}
+
+	return obsoleteApps
+}
+
+// appDrifted checks whether the current App differs from the desired one in
+// any of the fields set by release-operator. Labels and annotations applied
+// by release-operator before but not desired anymore, e.g. because they were
+// removed from the extra labels of a component, are drifted as well, so that
+// applying the desired App removes them.
+func appDrifted(desired, current appv1alpha1.App) bool {
+	for k, v := range desired.Labels {
+		if current.Labels[k] != v {
+			return true
+		}
+	}
+	for k, v := range desired.Annotations {
+		if current.Annotations[k] != v {
+			return true
+		}
+	}
+
+	appliedLabels, appliedAnnotations := appliedMetadataKeys(current)
+	for _, k := range appliedLabels {
+		if _, ok := desired.Labels[k]; !ok {
+			return true
+		}
+	}
+	for _, k := range appliedAnnotations {
+		if _, ok := desired.Annotations[k]; !ok {
+			return true
+		}
+	}
+
+	return desired.Spec.Catalog != current.Spec.Catalog ||
+		desired.Spec.Config != current.Spec.Config ||
+		!equality.Semantic.DeepEqual(desired.Spec.Install, current.Spec.Install) ||
+		desired.Spec.KubeConfig != current.Spec.KubeConfig ||
+		desired.Spec.Name != current.Spec.Name ||
+		desired.Spec.Namespace != current.Spec.Namespace ||
+		!equality.Semantic.DeepEqual(desired.Spec.Upgrade, current.Spec.Upgrade) ||
+		desired.Spec.UserConfig != current.Spec.UserConfig ||
+		desired.Spec.Version != current.Spec.Version
+}
+
+// appliedMetadataKeys returns the label and annotation keys of the given App
+// owned by release-operator according to its managed fields, which are the
+// ones it applied last.
+func appliedMetadataKeys(app appv1alpha1.App) ([]string, []string) {
+	var labels, annotations []string
+	for _, entry := range app.ManagedFields {
+		if entry.Manager != fieldManager || entry.Operation != metav1.ManagedFieldsOperationApply || entry.FieldsV1 == nil {
+			continue
+		}
+
+		var fields struct {
+			Metadata struct {
+				Labels      map[string]json.RawMessage `json:"f:labels"`
+				Annotations map[string]json.RawMessage `json:"f:annotations"`
+			} `json:"f:metadata"`
+		}
+		err := json.Unmarshal(entry.FieldsV1.Raw, &fields)
+		if err != nil {
+			continue
+		}
+
+		for k := range fields.Metadata.Labels {
+			if strings.HasPrefix(k, "f:") {
+				labels = append(labels, strings.TrimPrefix(k, "f:"))
+			}
+		}
+		for k := range fields.Metadata.Annotations {
+			if strings.HasPrefix(k, "f:") {
+				annotations = append(annotations, strings.TrimPrefix(k, "f:"))
+			}
+		}
+	}
+
+	return labels, annotations
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func formatAppConfig(config appv1alpha1.AppSpecConfig) string {
+	return fmt.Sprintf("ConfigMap %#q and Secret %#q", config.ConfigMap.Name, config.Secret.Name)
+}
+
+func findApp(name string, apps appv1alpha1.AppList) (appv1alpha1.App, bool) {
+	for _, app := range apps.Items {
+		if app.Name == name {
+			return app, true
+		}
+	}
+
+	return appv1alpha1.App{}, false
+}
+
+// configWaitSince returns when the given App started waiting for its Config,
+// which is when the Config was created. It returns false when the Config does
+// not exist yet.
+func configWaitSince(app appv1alpha1.App, configs corev1alpha1.ConfigList) (time.Time, bool) {
+	for _, config := range configs.Items {
+		if config.Name == app.Name {
+			return config.CreationTimestamp.Time, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// withAppConfig sets the config refs generated by config-controller for the
+// given App. It returns false when the Config is not ready yet.
+func withAppConfig(app *appv1alpha1.App, configs corev1alpha1.ConfigList) bool {
+	appConfig := key.GetAppConfig(*app, configs)
+	if appConfig.ConfigMapRef.Name == "" && appConfig.SecretRef.Name == "" {
+		return false
+	}
+
+	app.Spec.Config.ConfigMap.Name = appConfig.ConfigMapRef.Name
+	app.Spec.Config.ConfigMap.Namespace = appConfig.ConfigMapRef.Namespace
+	app.Spec.Config.Secret.Name = appConfig.SecretRef.Name
+	app.Spec.Config.Secret.Namespace = appConfig.SecretRef.Namespace
+
+	return true
+}
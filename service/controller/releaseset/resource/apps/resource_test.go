@@ -0,0 +1,1035 @@
+package apps
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	corev1alpha1 "github.com/giantswarm/config-controller/api/v1alpha1"
+	"github.com/giantswarm/k8sclient/v7/pkg/k8sclienttest"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
+	"github.com/giantswarm/release-operator/v4/service/controller/controllercontext"
+	"github.com/giantswarm/release-operator/v4/service/controller/inventory"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
+)
+
+var testComponents = []releasev1alpha1.ReleaseSpecComponent{
+	{
+		Name:    "test",
+		Version: "1.0.0",
+	},
+	{
+		Name:    "abc",
+		Version: "123.0.0",
+	},
+	{
+		Name:    "other",
+		Version: "2.0.0",
+	},
+}
+
+func Test_calculateMissingApps(t *testing.T) {
+	testCases := []struct {
+		name         string
+		operators    map[string]releasev1alpha1.ReleaseSpecComponent
+		apps         appv1alpha1.AppList
+		expectedApps appv1alpha1.AppList
+	}{
+		{
+			name: "case 0: an app is missing",
+			operators: map[string]releasev1alpha1.ReleaseSpecComponent{
+				key.BuildAppName(testComponents[0]): testComponents[0],
+				key.BuildAppName(testComponents[1]): testComponents[1],
+				key.BuildAppName(testComponents[2]): testComponents[2],
+			},
+			apps: appv1alpha1.AppList{
+				Items: []appv1alpha1.App{
+					appForComponent(testComponents[0]),
+				},
+			},
+
+			expectedApps: appv1alpha1.AppList{
+				Items: []appv1alpha1.App{
+					key.ConstructApp(testComponents[1]),
+					key.ConstructApp(testComponents[2]),
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			resultApps := calculateMissingApps(tc.operators, tc.apps)
+
+			sortSlice := func(slice []appv1alpha1.App) {
+				sort.SliceStable(slice, func(i, j int) bool { return slice[i].Name < slice[j].Name })
+			}
+
+			// calculateMissingApps iterates over a map -> random order
+			sortSlice(tc.expectedApps.Items)
+			sortSlice(resultApps.Items)
+
+			if !cmp.Equal(resultApps, tc.expectedApps) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedApps, resultApps))
+			}
+		})
+	}
+}
+
+func Test_calculateObsoleteApps(t *testing.T) {
+	testCases := []struct {
+		name         string
+		operators    map[string]releasev1alpha1.ReleaseSpecComponent
+		apps         appv1alpha1.AppList
+		expectedApps appv1alpha1.AppList
+	}{
+		{
+			name: "case 0: there is an obsolete app",
+			operators: map[string]releasev1alpha1.ReleaseSpecComponent{
+				key.BuildAppName(testComponents[0]): testComponents[0],
+				key.BuildAppName(testComponents[1]): testComponents[1],
+			},
+			apps: appv1alpha1.AppList{
+				Items: []appv1alpha1.App{
+					key.ConstructApp(testComponents[0]),
+					key.ConstructApp(testComponents[1]),
+					key.ConstructApp(testComponents[2]),
+				},
+			},
+			expectedApps: appv1alpha1.AppList{
+				Items: []appv1alpha1.App{
+					key.ConstructApp(testComponents[2]),
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			resultApps := calculateObsoleteApps(tc.operators, nil, tc.apps)
+
+			if !cmp.Equal(resultApps, tc.expectedApps) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedApps, resultApps))
+			}
+		})
+	}
+}
+
+func Test_calculateDriftedApps(t *testing.T) {
+	component := releasev1alpha1.ReleaseSpecComponent{
+		Catalog: "control-plane-catalog",
+		Name:    "app-operator",
+		Version: "1.0.0",
+	}
+	configs := corev1alpha1.ConfigList{
+		Items: []corev1alpha1.Config{*readyConfigForComponent(component)},
+	}
+	desired := key.ConstructApp(component)
+	withAppConfig(&desired, configs)
+
+	testCases := []struct {
+		name         string
+		apps         appv1alpha1.AppList
+		configs      corev1alpha1.ConfigList
+		expectedApps []string
+	}{
+		{
+			name:         "case 0: app matching the desired state is not drifted",
+			apps:         appv1alpha1.AppList{Items: []appv1alpha1.App{desired}},
+			configs:      configs,
+			expectedApps: nil,
+		},
+		{
+			name: "case 1: app with a manually changed catalog is drifted",
+			apps: appv1alpha1.AppList{Items: []appv1alpha1.App{func() appv1alpha1.App {
+				app := *desired.DeepCopy()
+				app.Spec.Catalog = "default"
+				return app
+			}()}},
+			configs:      configs,
+			expectedApps: []string{"app-operator-1.0.0"},
+		},
+		{
+			name: "case 2: app with a removed label is drifted",
+			apps: appv1alpha1.AppList{Items: []appv1alpha1.App{func() appv1alpha1.App {
+				app := *desired.DeepCopy()
+				delete(app.Labels, key.LabelManagedBy)
+				return app
+			}()}},
+			configs:      configs,
+			expectedApps: []string{"app-operator-1.0.0"},
+		},
+		{
+			name: "case 3: app with additional labels is not drifted",
+			apps: appv1alpha1.AppList{Items: []appv1alpha1.App{func() appv1alpha1.App {
+				app := *desired.DeepCopy()
+				app.Labels["foo"] = "bar"
+				return app
+			}()}},
+			configs:      configs,
+			expectedApps: nil,
+		},
+		{
+			name: "case 4: app with a manually set upgrade timeout is drifted",
+			apps: appv1alpha1.AppList{Items: []appv1alpha1.App{func() appv1alpha1.App {
+				app := *desired.DeepCopy()
+				app.Spec.Upgrade.Timeout = &metav1.Duration{Duration: time.Minute}
+				return app
+			}()}},
+			configs:      configs,
+			expectedApps: []string{"app-operator-1.0.0"},
+		},
+		{
+			name:         "case 5: current config refs are kept when the config is not ready",
+			apps:         appv1alpha1.AppList{Items: []appv1alpha1.App{desired}},
+			configs:      corev1alpha1.ConfigList{},
+			expectedApps: nil,
+		},
+		{
+			name:         "case 6: missing apps are not drifted",
+			apps:         appv1alpha1.AppList{},
+			configs:      configs,
+			expectedApps: nil,
+		},
+		{
+			name: "case 7: app with an extra label removed from the component is drifted",
+			apps: appv1alpha1.AppList{Items: []appv1alpha1.App{func() appv1alpha1.App {
+				app := *desired.DeepCopy()
+				app.Labels["team"] = "honeybadger"
+				app.Annotations = map[string]string{"owner": "honeybadger"}
+				app.ManagedFields = []metav1.ManagedFieldsEntry{
+					managedFields(fieldManager, metav1.ManagedFieldsOperationApply, `{"f:metadata":{"f:labels":{"f:team":{}},"f:annotations":{"f:owner":{}}}}`),
+				}
+				return app
+			}()}},
+			configs:      configs,
+			expectedApps: []string{"app-operator-1.0.0"},
+		},
+		{
+			name: "case 8: app with an extra annotation removed from the component is drifted",
+			apps: appv1alpha1.AppList{Items: []appv1alpha1.App{func() appv1alpha1.App {
+				app := *desired.DeepCopy()
+				app.Annotations = map[string]string{"owner": "honeybadger"}
+				app.ManagedFields = []metav1.ManagedFieldsEntry{
+					managedFields(fieldManager, metav1.ManagedFieldsOperationApply, `{"f:metadata":{"f:annotations":{"f:owner":{}}}}`),
+				}
+				return app
+			}()}},
+			configs:      configs,
+			expectedApps: []string{"app-operator-1.0.0"},
+		},
+		{
+			name: "case 9: app with a label owned by another field manager is not drifted",
+			apps: appv1alpha1.AppList{Items: []appv1alpha1.App{func() appv1alpha1.App {
+				app := *desired.DeepCopy()
+				app.Labels["team"] = "honeybadger"
+				app.ManagedFields = []metav1.ManagedFieldsEntry{
+					managedFields("kubectl", metav1.ManagedFieldsOperationUpdate, `{"f:metadata":{"f:labels":{"f:team":{}}}}`),
+				}
+				return app
+			}()}},
+			configs:      configs,
+			expectedApps: nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			components := map[string]releasev1alpha1.ReleaseSpecComponent{
+				key.BuildAppName(component): component,
+			}
+
+			var names []string
+			for _, app := range calculateDriftedApps(components, tc.apps, tc.configs).Items {
+				names = append(names, app.Name)
+			}
+
+			if !cmp.Equal(names, tc.expectedApps) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedApps, names))
+			}
+		})
+	}
+}
+
+func Test_Resource_ensureState_revertsDrift(t *testing.T) {
+	component := releasev1alpha1.ReleaseSpecComponent{
+		Catalog:               "control-plane-catalog",
+		Name:                  "app-operator",
+		ReleaseOperatorDeploy: true,
+		Version:               "1.0.0",
+	}
+	config := readyConfigForComponent(component)
+
+	drifted := key.ConstructApp(component)
+	drifted.Spec.Catalog = "default"
+	drifted.Spec.KubeConfig.InCluster = false
+
+	r, ctrlClient, _ := newTestResource(t, false, &drifted, config)
+
+	ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+		Components: map[string]releasev1alpha1.ReleaseSpecComponent{
+			key.BuildAppName(component): component,
+		},
+	})
+
+	err := r.ensureState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var app appv1alpha1.App
+	err = ctrlClient.Get(ctx, client.ObjectKeyFromObject(&drifted), &app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(app.Spec.Catalog, component.Catalog) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(component.Catalog, app.Spec.Catalog))
+	}
+	if !app.Spec.KubeConfig.InCluster {
+		t.Fatalf("expected app %#q to use in cluster credentials", app.Name)
+	}
+	if !cmp.Equal(app.Spec.Config.ConfigMap.Name, config.Status.Config.ConfigMapRef.Name) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(config.Status.Config.ConfigMapRef.Name, app.Spec.Config.ConfigMap.Name))
+	}
+}
+
+func Test_Resource_ensureState_configRotation(t *testing.T) {
+	component := releasev1alpha1.ReleaseSpecComponent{
+		Catalog:               "control-plane-catalog",
+		Name:                  "app-operator",
+		ReleaseOperatorDeploy: true,
+		Version:               "1.0.0",
+	}
+	config := readyConfigForComponent(component)
+
+	r, ctrlClient, recorder := newTestResource(t, false, config)
+
+	// Events are recorded on every release deploying the component.
+	ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+		Releases: []releasev1alpha1.Release{
+			newTestReleaseWithComponent("v1.0.0", component),
+			newTestReleaseWithComponent("v2.0.0", component),
+		},
+		Components: map[string]releasev1alpha1.ReleaseSpecComponent{
+			key.BuildAppName(component): component,
+		},
+	})
+
+	err := r.ensureState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var app appv1alpha1.App
+	err = ctrlClient.Get(ctx, client.ObjectKey{Namespace: config.Namespace, Name: key.BuildAppName(component)}, &app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(app.Spec.Config.ConfigMap.Name, "app-operator-1.0.0-configmap") {
+		t.Fatalf("\n\n%s\n", cmp.Diff("app-operator-1.0.0-configmap", app.Spec.Config.ConfigMap.Name))
+	}
+
+	// config-controller regenerates the configuration into new objects.
+	err = ctrlClient.Get(ctx, client.ObjectKeyFromObject(config), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Status.Config.ConfigMapRef.Name = "app-operator-1.0.0-configmap-rotated"
+	config.Status.Config.SecretRef.Name = "app-operator-1.0.0-secret-rotated"
+	err = ctrlClient.Status().Update(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.ensureState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ctrlClient.Get(ctx, client.ObjectKeyFromObject(&app), &app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(app.Spec.Config.ConfigMap.Name, "app-operator-1.0.0-configmap-rotated") {
+		t.Fatalf("\n\n%s\n", cmp.Diff("app-operator-1.0.0-configmap-rotated", app.Spec.Config.ConfigMap.Name))
+	}
+	if !cmp.Equal(app.Spec.Config.Secret.Name, "app-operator-1.0.0-secret-rotated") {
+		t.Fatalf("\n\n%s\n", cmp.Diff("app-operator-1.0.0-secret-rotated", app.Spec.Config.Secret.Name))
+	}
+
+	var events []string
+	close(recorder.Events)
+	for e := range recorder.Events {
+		events = append(events, e)
+	}
+	expectedEvents := []string{
+		"Normal AppCreated Created App `app-operator-1.0.0` in namespace `giantswarm`",
+		"Normal AppCreated Created App `app-operator-1.0.0` in namespace `giantswarm`",
+		"Normal AppUpdated Updated drifted App `app-operator-1.0.0` in namespace `giantswarm`",
+		"Normal AppUpdated Updated drifted App `app-operator-1.0.0` in namespace `giantswarm`",
+		"Normal ConfigUpdated App `app-operator-1.0.0` config changed from ConfigMap `app-operator-1.0.0-configmap` and Secret `app-operator-1.0.0-secret` to ConfigMap `app-operator-1.0.0-configmap-rotated` and Secret `app-operator-1.0.0-secret-rotated`",
+		"Normal ConfigUpdated App `app-operator-1.0.0` config changed from ConfigMap `app-operator-1.0.0-configmap` and Secret `app-operator-1.0.0-secret` to ConfigMap `app-operator-1.0.0-configmap-rotated` and Secret `app-operator-1.0.0-secret-rotated`",
+	}
+	if !cmp.Equal(events, expectedEvents) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(expectedEvents, events))
+	}
+}
+
+func Test_Resource_ensureState_releaseAppDependencies(t *testing.T) {
+	releaseApps := []releasev1alpha1.ReleaseSpecApp{
+		{Name: "a", Version: "1.0.0", ReleaseOperatorDeploy: true, DependsOn: []string{"b"}},
+		{Name: "b", Version: "1.0.0", ReleaseOperatorDeploy: true},
+		{Name: "c", Version: "1.0.0", ReleaseOperatorDeploy: true, DependsOn: []string{"d"}},
+		{Name: "d", Version: "1.0.0", ReleaseOperatorDeploy: true, DependsOn: []string{"c"}},
+	}
+	releases := releasev1alpha1.ReleaseList{
+		Items: []releasev1alpha1.Release{
+			{Spec: releasev1alpha1.ReleaseSpec{Apps: releaseApps}},
+		},
+	}
+
+	r, ctrlClient, _ := newTestResource(t, false)
+
+	ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+		Apps:            key.ExtractApps(releases),
+		AppDependencies: key.ExtractAppDependencies(releases),
+	})
+
+	appNames := func() []string {
+		var apps appv1alpha1.AppList
+		err := ctrlClient.List(ctx, &apps)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, app := range apps.Items {
+			names = append(names, app.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	// Only the app without dependencies is created, apps in the cycle are
+	// never created.
+	err := r.ensureState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(appNames(), []string{"b-1.0.0"}) {
+		t.Fatalf("\n\n%s\n", cmp.Diff([]string{"b-1.0.0"}, appNames()))
+	}
+
+	// The dependant app is created once its dependency is deployed.
+	var b appv1alpha1.App
+	err = ctrlClient.Get(ctx, client.ObjectKey{Namespace: key.Namespace, Name: "b-1.0.0"}, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Status.Release.Status = key.AppStatusDeployed
+	err = ctrlClient.Status().Update(ctx, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.ensureState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(appNames(), []string{"a-1.0.0", "b-1.0.0"}) {
+		t.Fatalf("\n\n%s\n", cmp.Diff([]string{"a-1.0.0", "b-1.0.0"}, appNames()))
+	}
+}
+
+func Test_Resource_ensureState_componentDependencies(t *testing.T) {
+	certOperator := releasev1alpha1.ReleaseSpecComponent{
+		Catalog:               "control-plane-catalog",
+		Name:                  "cert-operator",
+		ReleaseOperatorDeploy: true,
+		Version:               "1.0.0",
+	}
+	clusterOperator := releasev1alpha1.ReleaseSpecComponent{
+		Catalog:               "control-plane-catalog",
+		DependsOn:             []string{"cert-operator"},
+		Name:                  "cluster-operator",
+		ReleaseOperatorDeploy: true,
+		Version:               "2.0.0",
+	}
+	releases := releasev1alpha1.ReleaseList{
+		Items: []releasev1alpha1.Release{
+			{Spec: releasev1alpha1.ReleaseSpec{Components: []releasev1alpha1.ReleaseSpecComponent{certOperator, clusterOperator}}},
+		},
+	}
+
+	r, ctrlClient, _ := newTestResource(t, false, readyConfigForComponent(certOperator), readyConfigForComponent(clusterOperator))
+
+	ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+		Components:            key.ExtractComponents(releases),
+		ComponentDependencies: key.ExtractComponentDependencies(releases),
+	})
+
+	appNames := func() []string {
+		var apps appv1alpha1.AppList
+		err := ctrlClient.List(ctx, &apps)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, app := range apps.Items {
+			names = append(names, app.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	err := r.ensureState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(appNames(), []string{"cert-operator-1.0.0"}) {
+		t.Fatalf("\n\n%s\n", cmp.Diff([]string{"cert-operator-1.0.0"}, appNames()))
+	}
+
+	var app appv1alpha1.App
+	err = ctrlClient.Get(ctx, client.ObjectKey{Namespace: key.Namespace, Name: "cert-operator-1.0.0"}, &app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Status.Release.Status = key.AppStatusDeployed
+	err = ctrlClient.Status().Update(ctx, &app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.ensureState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(appNames(), []string{"cert-operator-1.0.0", "cluster-operator-2.0.0"}) {
+		t.Fatalf("\n\n%s\n", cmp.Diff([]string{"cert-operator-1.0.0", "cluster-operator-2.0.0"}, appNames()))
+	}
+}
+
+func Test_Resource_ensureState_skippedEvents(t *testing.T) {
+	certOperator := releasev1alpha1.ReleaseSpecComponent{
+		Catalog:               "control-plane-catalog",
+		Name:                  "cert-operator",
+		ReleaseOperatorDeploy: true,
+		Version:               "1.0.0",
+	}
+	clusterOperator := releasev1alpha1.ReleaseSpecComponent{
+		Catalog:               "control-plane-catalog",
+		DependsOn:             []string{"cert-operator"},
+		Name:                  "cluster-operator",
+		ReleaseOperatorDeploy: true,
+		Version:               "2.0.0",
+	}
+	releases := releasev1alpha1.ReleaseList{
+		Items: []releasev1alpha1.Release{
+			{Spec: releasev1alpha1.ReleaseSpec{Components: []releasev1alpha1.ReleaseSpecComponent{certOperator, clusterOperator}}},
+		},
+	}
+
+	r, _, recorder := newTestResource(t, false, readyConfigForComponent(certOperator), readyConfigForComponent(clusterOperator))
+
+	ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+		Components:            key.ExtractComponents(releases),
+		ComponentDependencies: key.ExtractComponentDependencies(releases),
+	})
+
+	skippedEvents := func() []string {
+		var events []string
+		for {
+			select {
+			case e := <-recorder.Events:
+				if strings.Contains(e, "AppSkipped") {
+					events = append(events, e)
+				}
+			default:
+				return events
+			}
+		}
+	}
+
+	// cluster-operator keeps waiting for cert-operator, which is created but
+	// not deployed, over several loops.
+	expectedEvents := []string{
+		"Normal AppSkipped App `cluster-operator-2.0.0` is not created until apps cert-operator-1.0.0 are deployed",
+	}
+	for i := 0; i < 3; i++ {
+		err := r.ensureState(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The event is only emitted when the app starts being skipped.
+	events := skippedEvents()
+	if !cmp.Equal(events, expectedEvents) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(expectedEvents, events))
+	}
+}
+
+func Test_Resource_ensureState_configWait(t *testing.T) {
+	component := releasev1alpha1.ReleaseSpecComponent{
+		Catalog:               "control-plane-catalog",
+		Name:                  "app-operator",
+		ReleaseOperatorDeploy: true,
+		Version:               "1.0.0",
+	}
+
+	testCases := []struct {
+		name           string
+		configCreated  time.Time
+		expectedEvents []string
+	}{
+		{
+			name:          "case 0: app is skipped within the threshold",
+			configCreated: time.Now().Add(-5 * time.Minute),
+			expectedEvents: []string{
+				"Normal AppSkipped App `app-operator-1.0.0` is not created until its config is generated",
+			},
+		},
+		{
+			name:          "case 1: warning event is emitted on the release after the threshold",
+			configCreated: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			expectedEvents: []string{
+				"Warning ConfigNotReady App `app-operator-1.0.0` has been waiting for config `app-operator-1.0.0` since 2026-01-02T03:04:05Z",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			config := key.ConstructConfig(component)
+			config.CreationTimestamp = metav1.NewTime(tc.configCreated)
+
+			r, ctrlClient, recorder := newTestResource(t, false, &config)
+
+			ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+				Releases: []releasev1alpha1.Release{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "v1.0.0",
+						},
+						Spec: releasev1alpha1.ReleaseSpec{
+							Components: []releasev1alpha1.ReleaseSpecComponent{component},
+						},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "v2.0.0",
+						},
+					},
+				},
+				Components: map[string]releasev1alpha1.ReleaseSpecComponent{
+					key.BuildAppName(component): component,
+				},
+			})
+
+			err := r.ensureState(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var apps appv1alpha1.AppList
+			err = ctrlClient.List(ctx, &apps)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(len(apps.Items), 0) {
+				t.Fatalf("expected no apps, got %#v", apps.Items)
+			}
+
+			var events []string
+			close(recorder.Events)
+			for e := range recorder.Events {
+				events = append(events, e)
+			}
+			if !cmp.Equal(events, tc.expectedEvents) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedEvents, events))
+			}
+		})
+	}
+}
+
+func Test_Resource_ensureState_skipConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		skipConfig  bool
+		skipConfigs bool
+	}{
+		{
+			name:       "case 0: component skipping config generation is created without config",
+			skipConfig: true,
+		},
+		{
+			name:        "case 1: all components are created without config when skipped operator wide",
+			skipConfigs: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			component := releasev1alpha1.ReleaseSpecComponent{
+				Catalog:               "control-plane-catalog",
+				Name:                  "app-operator",
+				ReleaseOperatorDeploy: true,
+				SkipConfig:            tc.skipConfig,
+				Version:               "1.0.0",
+			}
+
+			r, ctrlClient, _ := newTestResource(t, false)
+			r.skipConfigs = tc.skipConfigs
+
+			ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+				Components: map[string]releasev1alpha1.ReleaseSpecComponent{
+					key.BuildAppName(component): component,
+				},
+			})
+
+			err := r.ensureState(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var app appv1alpha1.App
+			err = ctrlClient.Get(ctx, client.ObjectKey{Namespace: key.Namespace, Name: key.BuildAppName(component)}, &app)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(app.Spec.Config, appv1alpha1.AppSpecConfig{}) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(appv1alpha1.AppSpecConfig{}, app.Spec.Config))
+			}
+		})
+	}
+}
+
+func Test_Resource_ensureState_userConfig(t *testing.T) {
+	component := releasev1alpha1.ReleaseSpecComponent{
+		Catalog:               "control-plane-catalog",
+		Name:                  "app-operator",
+		ReleaseOperatorDeploy: true,
+		UserConfig: &releasev1alpha1.ReleaseSpecComponentUserConfig{
+			ConfigMap: &releasev1alpha1.ReleaseSpecObjectReference{
+				Name:      "app-operator-user-values",
+				Namespace: "giantswarm",
+			},
+		},
+		Version: "1.0.0",
+	}
+
+	r, ctrlClient, recorder := newTestResource(t, false, readyConfigForComponent(component))
+
+	ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+		Components: map[string]releasev1alpha1.ReleaseSpecComponent{
+			key.BuildAppName(component): component,
+		},
+	})
+
+	// The App is not created as long as the referenced ConfigMap is missing.
+	err := r.ensureState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var apps appv1alpha1.AppList
+	err = ctrlClient.List(ctx, &apps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(len(apps.Items), 0) {
+		t.Fatalf("expected no apps, got %#v", apps.Items)
+	}
+
+	expectedEvent := "Warning UserConfigNotFound App `app-operator-1.0.0` is not applied because user values ConfigMap giantswarm/app-operator-user-values does not exist"
+	if e := <-recorder.Events; !cmp.Equal(e, expectedEvent) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(expectedEvent, e))
+	}
+
+	// Only the metadata of the ConfigMap is read.
+	r.metadataClient = newTestMetadataClient(t, &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app-operator-user-values",
+			Namespace: "giantswarm",
+		},
+	})
+
+	err = r.ensureState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var app appv1alpha1.App
+	err = ctrlClient.Get(ctx, client.ObjectKey{Namespace: key.Namespace, Name: key.BuildAppName(component)}, &app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedUserConfig := appv1alpha1.AppSpecUserConfig{
+		ConfigMap: appv1alpha1.AppSpecUserConfigConfigMap{
+			Name:      "app-operator-user-values",
+			Namespace: "giantswarm",
+		},
+	}
+	if !cmp.Equal(app.Spec.UserConfig, expectedUserConfig) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(expectedUserConfig, app.Spec.UserConfig))
+	}
+}
+
+func Test_Resource_ensureState_userConfigSecret(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		checkUserConfigSecrets bool
+		expectedApps           int
+	}{
+		{
+			name:                   "case 0: app is not created while the referenced secret is missing",
+			checkUserConfigSecrets: true,
+			expectedApps:           0,
+		},
+		{
+			name:                   "case 1: app is created without checking the secret when disabled",
+			checkUserConfigSecrets: false,
+			expectedApps:           1,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			component := releasev1alpha1.ReleaseSpecComponent{
+				Catalog:               "control-plane-catalog",
+				Name:                  "app-operator",
+				ReleaseOperatorDeploy: true,
+				UserConfig: &releasev1alpha1.ReleaseSpecComponentUserConfig{
+					Secret: &releasev1alpha1.ReleaseSpecObjectReference{
+						Name:      "app-operator-user-secrets",
+						Namespace: "giantswarm",
+					},
+				},
+				Version: "1.0.0",
+			}
+
+			r, ctrlClient, _ := newTestResource(t, false, readyConfigForComponent(component))
+			r.checkUserConfigSecrets = tc.checkUserConfigSecrets
+
+			ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+				Components: map[string]releasev1alpha1.ReleaseSpecComponent{
+					key.BuildAppName(component): component,
+				},
+			})
+
+			err := r.ensureState(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var apps appv1alpha1.AppList
+			err = ctrlClient.List(ctx, &apps)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(len(apps.Items), tc.expectedApps) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedApps, len(apps.Items)))
+			}
+		})
+	}
+}
+
+func Test_Resource_ensureState_dryRun(t *testing.T) {
+	obsoleteApp := key.ConstructApp(testComponents[2])
+	component := releasev1alpha1.ReleaseSpecComponent{
+		Catalog:               "control-plane-catalog",
+		Name:                  "app-operator",
+		ReleaseOperatorDeploy: true,
+		Version:               "1.0.0",
+	}
+
+	r, ctrlClient, recorder := newTestResource(t, true, &obsoleteApp, readyConfigForComponent(component))
+
+	// Planned creates are recorded on the releases deploying the component,
+	// planned deletes of obsolete Apps on the App itself.
+	ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+		Releases: []releasev1alpha1.Release{
+			newTestReleaseWithComponent("v1.0.0", component),
+			newTestReleaseWithComponent("v2.0.0", component),
+		},
+		Components: map[string]releasev1alpha1.ReleaseSpecComponent{
+			key.BuildAppName(component): component,
+		},
+	})
+
+	err := r.ensureState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var apps appv1alpha1.AppList
+	err = ctrlClient.List(ctx, &apps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(len(apps.Items), 1) || !cmp.Equal(apps.Items[0].Name, obsoleteApp.Name) {
+		t.Fatalf("expected apps to be left untouched, got %#v", apps.Items)
+	}
+
+	var events []string
+	close(recorder.Events)
+	for e := range recorder.Events {
+		events = append(events, e)
+	}
+	expectedEvents := []string{
+		"Normal DryRunDelete App `other-2.0.0` would be deleted",
+		"Normal DryRunCreate App `app-operator-1.0.0` would be created",
+		"Normal DryRunCreate App `app-operator-1.0.0` would be created",
+	}
+	if !cmp.Equal(events, expectedEvents) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(expectedEvents, events))
+	}
+}
+
+func newTestResource(t *testing.T, dryRun bool, objects ...client.Object) (*Resource, client.Client, *record.FakeRecorder) {
+	scheme := runtime.NewScheme()
+	err := appv1alpha1.AddToScheme(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = corev1alpha1.AddToScheme(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = corev1.AddToScheme(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctrlClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(&appv1alpha1.App{}, &corev1alpha1.Config{}).Build()
+	recorder := record.NewFakeRecorder(10)
+
+	r, err := New(Config{
+		EventRecorder: recorder,
+		Inventory:     testInventory{},
+		K8sClient: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+			CtrlClient: ctrlClient,
+		}),
+		Logger:         microloggertest.New(),
+		MetadataClient: newTestMetadataClient(t),
+
+		CheckUserConfigSecrets: true,
+		DryRun:                 dryRun,
+		MaxDeletions:           DefaultMaxDeletions,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return r, ctrlClient, recorder
+}
+
+func newTestMetadataClient(t *testing.T, objects ...runtime.Object) *metadatafake.FakeMetadataClient {
+	scheme := metadatafake.NewTestScheme()
+	err := metav1.AddMetaToScheme(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return metadatafake.NewSimpleMetadataClient(scheme, objects...)
+}
+
+func managedFields(manager string, operation metav1.ManagedFieldsOperationType, fields string) metav1.ManagedFieldsEntry {
+	return metav1.ManagedFieldsEntry{
+		Manager:    manager,
+		Operation:  operation,
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(fields)},
+	}
+}
+
+func newTestReleaseWithComponent(name string, component releasev1alpha1.ReleaseSpecComponent) releasev1alpha1.Release {
+	return releasev1alpha1.Release{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: releasev1alpha1.ReleaseSpec{
+			Components: []releasev1alpha1.ReleaseSpecComponent{component},
+		},
+	}
+}
+
+// testInventory is an inventory.Interface returning a fixed set of clusters.
+type testInventory struct {
+	clusters []inventory.Cluster
+}
+
+func (i testInventory) Snapshot(ctx context.Context) (inventory.Snapshot, error) {
+	return inventory.Snapshot{Clusters: i.clusters}, nil
+}
+
+// readyConfigForComponent returns the Config of the given component as it
+// looks once config-controller generated its configuration.
+func readyConfigForComponent(component releasev1alpha1.ReleaseSpecComponent) *corev1alpha1.Config {
+	config := key.ConstructConfig(component)
+	config.Status = corev1alpha1.ConfigStatus{
+		App: corev1alpha1.ConfigStatusApp{
+			Catalog: component.Catalog,
+			Name:    component.Name,
+			Version: key.GetComponentRef(component),
+		},
+		Config: corev1alpha1.ConfigStatusConfig{
+			ConfigMapRef: corev1alpha1.ConfigStatusConfigConfigMapRef{
+				Name:      config.Name + "-configmap",
+				Namespace: config.Namespace,
+			},
+			SecretRef: corev1alpha1.ConfigStatusConfigSecretRef{
+				Name:      config.Name + "-secret",
+				Namespace: config.Namespace,
+			},
+		},
+	}
+
+	return &config
+}
+
+func appForComponent(operator releasev1alpha1.ReleaseSpecComponent) appv1alpha1.App {
+	return appv1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: key.BuildAppName(operator),
+		},
+		Spec: appv1alpha1.AppSpec{
+			Name:    operator.Name,
+			Version: operator.Version,
+		},
+	}
+}
@@ -0,0 +1,35 @@
+package configs
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	namespace = "release_operator"
+	subsystem = "dry_run"
+)
+
+const (
+	labelAction = "action"
+)
+
+const (
+	actionCreate = "create"
+	actionDelete = "delete"
+)
+
+var (
+	plannedConfigsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "planned_configs",
+			Help:      "Number of Configs which would be created or deleted if dry-run mode was disabled.",
+		},
+		[]string{labelAction},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(plannedConfigsGauge)
+}
@@ -0,0 +1,213 @@
+package configs
+
+import (
+	"context"
+	"fmt"
+
+	corev1alpha1 "github.com/giantswarm/config-controller/api/v1alpha1"
+	"github.com/giantswarm/k8sclient/v7/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
+	"github.com/giantswarm/release-operator/v4/pkg/project"
+	"github.com/giantswarm/release-operator/v4/service/controller/controllercontext"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
+)
+
+const (
+	Name = "configs"
+)
+
+type Config struct {
+	EventRecorder record.EventRecorder
+	K8sClient     k8sclient.Interface
+	Logger        micrologger.Logger
+
+	// DryRun makes the resource only report planned changes as logs, events
+	// and metrics without applying them.
+	DryRun bool
+	// SkipConfigs disables the creation of Config CRs for all components,
+	// e.g. on installations without config-controller.
+	SkipConfigs bool
+}
+
+type Resource struct {
+	eventRecorder record.EventRecorder
+	k8sClient     k8sclient.Interface
+	logger        micrologger.Logger
+
+	dryRun      bool
+	skipConfigs bool
+}
+
+func New(config Config) (*Resource, error) {
+	if config.EventRecorder == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.EventRecorder must not be empty", config)
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	r := &Resource{
+		eventRecorder: config.EventRecorder,
+		k8sClient:     config.K8sClient,
+		logger:        config.Logger,
+
+		dryRun:      config.DryRun,
+		skipConfigs: config.SkipConfigs,
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
+
+func (r *Resource) ensureState(ctx context.Context) error {
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if r.skipConfigs {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not managing configs as config generation is skipped")
+		return nil
+	}
+
+	components := cc.Components
+
+	var configs corev1alpha1.ConfigList
+	{
+		err := r.k8sClient.CtrlClient().List(
+			ctx,
+			&configs,
+			&client.ListOptions{
+				LabelSelector: labels.SelectorFromSet(labels.Set{
+					key.LabelManagedBy: project.Name(),
+				}),
+			},
+		)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	configsToDelete := calculateObsoleteConfigs(components, configs)
+	configsToCreate := calculateMissingConfigs(components, configs)
+
+	if r.dryRun {
+		r.reportPlannedChanges(ctx, configsToCreate, configsToDelete, cc.Releases)
+		return nil
+	}
+
+	for i, config := range configsToDelete.Items {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleting config %#q in namespace %#q", config.Name, config.Namespace))
+
+		err := r.k8sClient.CtrlClient().Delete(
+			ctx,
+			&configsToDelete.Items[i],
+		)
+		if apierrors.IsNotFound(err) {
+			// fall through.
+		} else if err != nil {
+			r.recordEvent(cc.Releases, &configsToDelete.Items[i], corev1.EventTypeWarning, "ConfigDeleteFailed", "Failed to delete Config %#q in namespace %#q: %s", config.Name, config.Namespace, err)
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleted config %#q in namespace %#q", config.Name, config.Namespace))
+		r.recordEvent(cc.Releases, &configsToDelete.Items[i], corev1.EventTypeNormal, "ConfigDeleted", "Deleted Config %#q in namespace %#q", config.Name, config.Namespace)
+	}
+
+	for i, config := range configsToCreate.Items {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("creating config %#q in namespace %#q", config.Name, config.Namespace))
+
+		err := r.k8sClient.CtrlClient().Create(
+			ctx,
+			&configsToCreate.Items[i],
+		)
+		if apierrors.IsAlreadyExists(err) {
+			// fall through.
+		} else if err != nil {
+			r.recordEvent(cc.Releases, &configsToCreate.Items[i], corev1.EventTypeWarning, "ConfigCreateFailed", "Failed to create Config %#q in namespace %#q: %s", config.Name, config.Namespace, err)
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("created config %#q in namespace %#q", config.Name, config.Namespace))
+		r.recordEvent(cc.Releases, &configsToCreate.Items[i], corev1.EventTypeNormal, "ConfigCreated", "Created Config %#q in namespace %#q", config.Name, config.Namespace)
+	}
+
+	return nil
+}
+
+// recordEvent emits an event on every release deploying the component of the
+// given Config so that changes can be audited per release. Configs no release
+// references anymore, like obsolete Configs being deleted, get the event
+// themselves.
+func (r *Resource) recordEvent(releases []releasev1alpha1.Release, config *corev1alpha1.Config, eventtype, reason, messageFmt string, args ...interface{}) {
+	deploying := key.ReleasesDeployingApp(releases, config.Name)
+	if len(deploying) == 0 {
+		r.eventRecorder.Eventf(config, eventtype, reason, messageFmt, args...)
+		return
+	}
+
+	for i := range deploying {
+		r.eventRecorder.Eventf(&deploying[i], eventtype, reason, messageFmt, args...)
+	}
+}
+
+// reportPlannedChanges logs and emits events for the given Configs instead of
+// creating or deleting them, and exposes their number as metrics. Events are
+// recorded on the releases deploying the Configs since Configs which would be
+// created do not exist.
+func (r *Resource) reportPlannedChanges(ctx context.Context, configsToCreate, configsToDelete corev1alpha1.ConfigList, releases []releasev1alpha1.Release) {
+	for i, config := range configsToDelete.Items {
+		r.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("dry-run: would delete config %#q in namespace %#q", config.Name, config.Namespace))
+		r.recordEvent(releases, &configsToDelete.Items[i], corev1.EventTypeNormal, "DryRunDelete", "Config %#q would be deleted", config.Name)
+	}
+
+	for i, config := range configsToCreate.Items {
+		r.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("dry-run: would create config %#q in namespace %#q", config.Name, config.Namespace))
+		r.recordEvent(releases, &configsToCreate.Items[i], corev1.EventTypeNormal, "DryRunCreate", "Config %#q would be created", config.Name)
+	}
+
+	plannedConfigsGauge.WithLabelValues(actionCreate).Set(float64(len(configsToCreate.Items)))
+	plannedConfigsGauge.WithLabelValues(actionDelete).Set(float64(len(configsToDelete.Items)))
+}
+
+func calculateMissingConfigs(components map[string]releasev1alpha1.ReleaseSpecComponent, configs corev1alpha1.ConfigList) corev1alpha1.ConfigList {
+	var missingConfigs corev1alpha1.ConfigList
+
+	for _, component := range components {
+		if component.SkipConfig {
+			continue
+		}
+		if !key.ComponentConfigCreated(component, configs.Items) {
+			missingConfig := key.ConstructConfig(component)
+			missingConfigs.Items = append(missingConfigs.Items, missingConfig)
+		}
+	}
+
+	return missingConfigs
+}
+
+func calculateObsoleteConfigs(components map[string]releasev1alpha1.ReleaseSpecComponent, configs corev1alpha1.ConfigList) corev1alpha1.ConfigList {
+	var obsoleteConfigs corev1alpha1.ConfigList
+
+	for _, config := range configs.Items {
+		if !key.ConfigReferenced(config, components) {
+			obsoleteConfigs.Items = append(obsoleteConfigs.Items, config)
+		}
+	}
+
+	return obsoleteConfigs
+}
@@ -60,6 +60,20 @@ func Test_calculateMissingConfigs(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "case 1: no config is created for a component skipping it",
+			operators: map[string]releasev1alpha1.ReleaseSpecComponent{
+				key.BuildConfigName(testComponents[0]): testComponents[0],
+				key.BuildConfigName(testComponents[1]): withSkipConfig(testComponents[1]),
+			},
+			configs: corev1alpha1.ConfigList{},
+
+			expectedConfigs: corev1alpha1.ConfigList{
+				Items: []corev1alpha1.Config{
+					key.ConstructConfig(testComponents[0]),
+				},
+			},
+		},
 	}
 
 	for i, tc := range testCases {
@@ -109,6 +123,24 @@ func Test_calculateObsoleteConfigs(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "case 1: the config of a component skipping it is obsolete",
+			operators: map[string]releasev1alpha1.ReleaseSpecComponent{
+				key.BuildConfigName(testComponents[0]): testComponents[0],
+				key.BuildConfigName(testComponents[1]): withSkipConfig(testComponents[1]),
+			},
+			configs: corev1alpha1.ConfigList{
+				Items: []corev1alpha1.Config{
+					key.ConstructConfig(testComponents[0]),
+					key.ConstructConfig(testComponents[1]),
+				},
+			},
+			expectedConfigs: corev1alpha1.ConfigList{
+				Items: []corev1alpha1.Config{
+					key.ConstructConfig(testComponents[1]),
+				},
+			},
+		},
 	}
 
 	for i, tc := range testCases {
@@ -142,3 +174,8 @@ func configForComponent(operator releasev1alpha1.ReleaseSpecComponent) corev1alp
 		},
 	}
 }
+
+func withSkipConfig(component releasev1alpha1.ReleaseSpecComponent) releasev1alpha1.ReleaseSpecComponent {
+	component.SkipConfig = true
+	return component
+}
@@ -0,0 +1,34 @@
+package releases
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	namespace = "release_operator"
+	subsystem = "releases"
+)
+
+var (
+	expiredReleasesGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "retention_expired",
+			Help:      "Number of unused deprecated Releases whose retention period expired in the last loop.",
+		},
+	)
+	deletedReleasesCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "deleted_total",
+			Help:      "Number of unused deprecated Releases deleted by the garbage collection.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(expiredReleasesGauge)
+	prometheus.MustRegister(deletedReleasesCounter)
+}
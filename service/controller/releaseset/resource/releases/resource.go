@@ -0,0 +1,165 @@
+package releases
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/giantswarm/k8sclient/v7/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+
+	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
+)
+
+const (
+	Name = "releases"
+)
+
+const (
+	// DefaultMaxDeletions is the number of expired Releases which may be
+	// deleted in a single loop when Config.MaxDeletions is negative.
+	DefaultMaxDeletions = 5
+)
+
+type Config struct {
+	EventRecorder record.EventRecorder
+	K8sClient     k8sclient.Interface
+	Logger        micrologger.Logger
+
+	// DryRun makes the resource only report expired Releases as logs and
+	// events without deleting them.
+	DryRun bool
+	// MaxDeletions is the number of expired Releases which may be deleted in
+	// a single loop. When more Releases expired, none of them is deleted, so
+	// zero disables deletions. Negative values use DefaultMaxDeletions.
+	MaxDeletions int
+	// Retention is the duration a deprecated Release must have been unused
+	// before it is deleted. Releases are never deleted when it is zero.
+	Retention time.Duration
+}
+
+// Resource garbage collects deprecated Releases which have not been used by
+// any cluster for the configured retention period.
+type Resource struct {
+	eventRecorder record.EventRecorder
+	k8sClient     k8sclient.Interface
+	logger        micrologger.Logger
+
+	dryRun       bool
+	maxDeletions int
+	retention    time.Duration
+}
+
+func New(config Config) (*Resource, error) {
+	if config.EventRecorder == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.EventRecorder must not be empty", config)
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.MaxDeletions < 0 {
+		config.MaxDeletions = DefaultMaxDeletions
+	}
+
+	r := &Resource{
+		eventRecorder: config.EventRecorder,
+		k8sClient:     config.K8sClient,
+		logger:        config.Logger,
+
+		dryRun:       config.DryRun,
+		maxDeletions: config.MaxDeletions,
+		retention:    config.Retention,
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
+
+func (r *Resource) ensureState(ctx context.Context) error {
+	if r.retention == 0 {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not garbage collecting releases as no retention is configured")
+		return nil
+	}
+
+	// Unused deprecated releases are not part of the controller context, so
+	// all releases are listed here.
+	var releases releasev1alpha1.ReleaseList
+	{
+		err := r.k8sClient.CtrlClient().List(ctx, &releases)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	expiredReleases := calculateExpiredReleases(releases, time.Now(), r.retention)
+	expiredReleasesGauge.Set(float64(len(expiredReleases)))
+
+	if r.dryRun {
+		for i, release := range expiredReleases {
+			r.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("dry-run: would delete release %#q", release.Name))
+			r.eventRecorder.Eventf(&expiredReleases[i], corev1.EventTypeNormal, "DryRunDelete", "Release %#q would be deleted", release.Name)
+		}
+		return nil
+	}
+
+	// Like for Apps, many Releases expiring at once usually points to a
+	// mistake, e.g. a wrong retention, so all deletions are held back then.
+	if len(expiredReleases) > r.maxDeletions {
+		for i, release := range expiredReleases {
+			message := fmt.Sprintf("Release %#q is not deleted because %d Releases would be deleted at once, which exceeds the limit of %d", release.Name, len(expiredReleases), r.maxDeletions)
+			r.logger.LogCtx(ctx, "level", "warning", "message", message)
+			r.eventRecorder.Eventf(&expiredReleases[i], corev1.EventTypeWarning, "DeletionHeldBack", "%s", message)
+		}
+		return nil
+	}
+
+	for i, release := range expiredReleases {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleting release %#q", release.Name))
+
+		err := r.k8sClient.CtrlClient().Delete(ctx, &expiredReleases[i])
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			r.eventRecorder.Eventf(&expiredReleases[i], corev1.EventTypeWarning, "ReleaseDeleteFailed", "Failed to delete Release %#q: %s", release.Name, err)
+			return microerror.Mask(err)
+		}
+
+		deletedReleasesCounter.Inc()
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleted release %#q", release.Name))
+		r.eventRecorder.Eventf(&expiredReleases[i], corev1.EventTypeNormal, "ReleaseDeleted", "Deleted Release %#q as it has not been used since %s", release.Name, release.Status.LastUsedTime.UTC().Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// calculateExpiredReleases returns the releases whose retention expired,
+// ordered by the time they were last used, oldest first.
+func calculateExpiredReleases(releases releasev1alpha1.ReleaseList, now time.Time, retention time.Duration) []releasev1alpha1.Release {
+	var expiredReleases []releasev1alpha1.Release
+
+	for _, release := range releases.Items {
+		if key.RetentionExpired(release, now, retention) {
+			expiredReleases = append(expiredReleases, release)
+		}
+	}
+
+	sort.SliceStable(expiredReleases, func(i, j int) bool {
+		return expiredReleases[i].Status.LastUsedTime.Before(expiredReleases[j].Status.LastUsedTime)
+	})
+
+	return expiredReleases
+}
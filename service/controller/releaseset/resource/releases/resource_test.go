@@ -0,0 +1,220 @@
+package releases
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/k8sclient/v7/pkg/k8sclienttest"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
+)
+
+func Test_calculateExpiredReleases(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	retention := 24 * time.Hour
+
+	testCases := []struct {
+		name             string
+		releases         releasev1alpha1.ReleaseList
+		expectedReleases []string
+	}{
+		{
+			name: "case 0: no releases expired",
+			releases: releasev1alpha1.ReleaseList{
+				Items: []releasev1alpha1.Release{
+					newTestRelease("v1.0.0", releasev1alpha1.StateActive, now.Add(-2*retention)),
+					newTestRelease("v2.0.0", releasev1alpha1.StateDeprecated, now.Add(-time.Hour)),
+				},
+			},
+			expectedReleases: nil,
+		},
+		{
+			name: "case 1: expired releases are ordered by their last used time",
+			releases: releasev1alpha1.ReleaseList{
+				Items: []releasev1alpha1.Release{
+					newTestRelease("v1.0.0", releasev1alpha1.StateDeprecated, now.Add(-2*retention)),
+					newTestRelease("v2.0.0", releasev1alpha1.StateDeprecated, now.Add(-3*retention)),
+					newTestRelease("v3.0.0", releasev1alpha1.StateDeprecated, now.Add(-time.Hour)),
+				},
+			},
+			expectedReleases: []string{"v2.0.0", "v1.0.0"},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			var names []string
+			for _, release := range calculateExpiredReleases(tc.releases, now, retention) {
+				names = append(names, release.Name)
+			}
+
+			if !cmp.Equal(names, tc.expectedReleases) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedReleases, names))
+			}
+		})
+	}
+}
+
+func Test_Resource_ensureState(t *testing.T) {
+	lastUsed := time.Now().Add(-48 * time.Hour)
+
+	testCases := []struct {
+		name             string
+		dryRun           bool
+		maxDeletions     int
+		retention        time.Duration
+		expectedReleases []string
+		expectedEvents   []string
+	}{
+		{
+			name:             "case 0: releases are kept without retention",
+			maxDeletions:     2,
+			retention:        0,
+			expectedReleases: []string{"v1.0.0", "v2.0.0", "v3.0.0", "v4.0.0"},
+		},
+		{
+			name:             "case 1: expired releases are deleted within the maximum per loop",
+			maxDeletions:     3,
+			retention:        24 * time.Hour,
+			expectedReleases: []string{"v4.0.0"},
+			expectedEvents: []string{
+				"Normal ReleaseDeleted Deleted Release `v1.0.0` as it has not been used since " + lastUsed.UTC().Format(time.RFC3339),
+				"Normal ReleaseDeleted Deleted Release `v2.0.0` as it has not been used since " + lastUsed.UTC().Format(time.RFC3339),
+				"Normal ReleaseDeleted Deleted Release `v3.0.0` as it has not been used since " + lastUsed.UTC().Format(time.RFC3339),
+			},
+		},
+		{
+			name:             "case 2: expired releases are only reported in dry-run mode",
+			dryRun:           true,
+			maxDeletions:     2,
+			retention:        24 * time.Hour,
+			expectedReleases: []string{"v1.0.0", "v2.0.0", "v3.0.0", "v4.0.0"},
+			expectedEvents: []string{
+				"Normal DryRunDelete Release `v1.0.0` would be deleted",
+				"Normal DryRunDelete Release `v2.0.0` would be deleted",
+				"Normal DryRunDelete Release `v3.0.0` would be deleted",
+			},
+		},
+		{
+			name:             "case 3: no releases are deleted when more than the maximum expired",
+			maxDeletions:     2,
+			retention:        24 * time.Hour,
+			expectedReleases: []string{"v1.0.0", "v2.0.0", "v3.0.0", "v4.0.0"},
+			expectedEvents: []string{
+				"Warning DeletionHeldBack Release `v1.0.0` is not deleted because 3 Releases would be deleted at once, which exceeds the limit of 2",
+				"Warning DeletionHeldBack Release `v2.0.0` is not deleted because 3 Releases would be deleted at once, which exceeds the limit of 2",
+				"Warning DeletionHeldBack Release `v3.0.0` is not deleted because 3 Releases would be deleted at once, which exceeds the limit of 2",
+			},
+		},
+		{
+			name:             "case 4: no releases are deleted when the maximum is zero",
+			maxDeletions:     0,
+			retention:        24 * time.Hour,
+			expectedReleases: []string{"v1.0.0", "v2.0.0", "v3.0.0", "v4.0.0"},
+			expectedEvents: []string{
+				"Warning DeletionHeldBack Release `v1.0.0` is not deleted because 3 Releases would be deleted at once, which exceeds the limit of 0",
+				"Warning DeletionHeldBack Release `v2.0.0` is not deleted because 3 Releases would be deleted at once, which exceeds the limit of 0",
+				"Warning DeletionHeldBack Release `v3.0.0` is not deleted because 3 Releases would be deleted at once, which exceeds the limit of 0",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			releases := []releasev1alpha1.Release{
+				newTestRelease("v1.0.0", releasev1alpha1.StateDeprecated, lastUsed),
+				newTestRelease("v2.0.0", releasev1alpha1.StateDeprecated, lastUsed),
+				newTestRelease("v3.0.0", releasev1alpha1.StateDeprecated, lastUsed),
+				newTestRelease("v4.0.0", releasev1alpha1.StateDeprecated, lastUsed),
+			}
+			releases[3].Annotations = map[string]string{
+				key.ProtectReleaseAnnotation: "true",
+			}
+
+			var objects []client.Object
+			for i := range releases {
+				objects = append(objects, &releases[i])
+			}
+
+			scheme := runtime.NewScheme()
+			err := releasev1alpha1.AddToScheme(scheme)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctrlClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			recorder := record.NewFakeRecorder(10)
+
+			r, err := New(Config{
+				EventRecorder: recorder,
+				K8sClient: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+					CtrlClient: ctrlClient,
+				}),
+				Logger: microloggertest.New(),
+
+				DryRun:       tc.dryRun,
+				MaxDeletions: tc.maxDeletions,
+				Retention:    tc.retention,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = r.ensureState(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var remaining releasev1alpha1.ReleaseList
+			err = ctrlClient.List(context.Background(), &remaining)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, release := range remaining.Items {
+				names = append(names, release.Name)
+			}
+			if !cmp.Equal(names, tc.expectedReleases) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedReleases, names))
+			}
+
+			close(recorder.Events)
+			var events []string
+			for e := range recorder.Events {
+				events = append(events, e)
+			}
+			if !cmp.Equal(events, tc.expectedEvents) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedEvents, events))
+			}
+		})
+	}
+}
+
+func newTestRelease(name string, state releasev1alpha1.ReleaseState, lastUsed time.Time) releasev1alpha1.Release {
+	return releasev1alpha1.Release{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: releasev1alpha1.ReleaseSpec{
+			State: state,
+		},
+		Status: releasev1alpha1.ReleaseStatus{
+			LastUsedTime: &metav1.Time{Time: lastUsed},
+		},
+	}
+}
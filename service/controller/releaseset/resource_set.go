@@ -0,0 +1,130 @@
+package releaseset
+
+import (
+	"time"
+
+	"github.com/giantswarm/k8sclient/v7/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/operatorkit/v7/pkg/resource"
+	"github.com/giantswarm/operatorkit/v7/pkg/resource/wrapper/metricsresource"
+	"github.com/giantswarm/operatorkit/v7/pkg/resource/wrapper/retryresource"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/giantswarm/release-operator/v4/service/controller/inventory"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
+	"github.com/giantswarm/release-operator/v4/service/controller/releaseset/resource/apps"
+	"github.com/giantswarm/release-operator/v4/service/controller/releaseset/resource/configs"
+	"github.com/giantswarm/release-operator/v4/service/controller/releaseset/resource/releases"
+)
+
+type ResourceSetConfig struct {
+	EventRecorder  record.EventRecorder
+	Inventory      inventory.Interface
+	K8sClient      k8sclient.Interface
+	Logger         micrologger.Logger
+	MetadataClient metadata.Interface
+
+	CheckUserConfigSecrets bool
+	ComponentDefaults      key.ComponentDefaults
+	ConfigWaitThreshold    time.Duration
+	DryRun                 bool
+	MaxAppDeletions        int
+	MaxReleaseDeletions    int
+	ReleaseRetention       time.Duration
+	SkipConfigs            bool
+}
+
+func NewResourceSet(config ResourceSetConfig) ([]resource.Interface, error) {
+	var err error
+
+	var appsResource resource.Interface
+	{
+		c := apps.Config{
+			EventRecorder:  config.EventRecorder,
+			Inventory:      config.Inventory,
+			K8sClient:      config.K8sClient,
+			Logger:         config.Logger,
+			MetadataClient: config.MetadataClient,
+
+			CheckUserConfigSecrets: config.CheckUserConfigSecrets,
+			ComponentDefaults:      config.ComponentDefaults,
+			ConfigWaitThreshold:    config.ConfigWaitThreshold,
+			DryRun:                 config.DryRun,
+			MaxDeletions:           config.MaxAppDeletions,
+			SkipConfigs:            config.SkipConfigs,
+		}
+
+		appsResource, err = apps.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var configsResource resource.Interface
+	{
+		c := configs.Config{
+			EventRecorder: config.EventRecorder,
+			K8sClient:     config.K8sClient,
+			Logger:        config.Logger,
+
+			DryRun:      config.DryRun,
+			SkipConfigs: config.SkipConfigs,
+		}
+
+		configsResource, err = configs.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var releasesResource resource.Interface
+	{
+		c := releases.Config{
+			EventRecorder: config.EventRecorder,
+			K8sClient:     config.K8sClient,
+			Logger:        config.Logger,
+
+			DryRun:       config.DryRun,
+			MaxDeletions: config.MaxReleaseDeletions,
+			Retention:    config.ReleaseRetention,
+		}
+
+		releasesResource, err = releases.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// Configs are created first so that config-controller can start
+	// generating them before the Apps referencing them are created. Releases
+	// are garbage collected last, their Apps and Configs are already gone.
+	resources := []resource.Interface{
+		configsResource,
+		appsResource,
+		releasesResource,
+	}
+
+	{
+		c := retryresource.WrapConfig{
+			Logger: config.Logger,
+		}
+
+		resources, err = retryresource.Wrap(resources, c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	{
+		c := metricsresource.WrapConfig{}
+
+		resources, err = metricsresource.Wrap(resources, c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return resources, nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sync"
 
@@ -13,13 +14,20 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 
 	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
 	"github.com/giantswarm/release-operator/v4/flag"
 	"github.com/giantswarm/release-operator/v4/pkg/project"
 	"github.com/giantswarm/release-operator/v4/service/collector"
 	"github.com/giantswarm/release-operator/v4/service/controller"
+	"github.com/giantswarm/release-operator/v4/service/controller/inventory"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
+	"github.com/giantswarm/release-operator/v4/service/webhook"
 )
 
 // Config represents the configuration used to create a new service.
@@ -32,11 +40,14 @@ type Config struct {
 
 // Service is a type providing implementation of microkit service interface.
 type Service struct {
-	Version *version.Service
+	ReleaseMutator   *webhook.ReleaseMutator
+	ReleaseValidator *webhook.ReleaseValidator
+	Version          *version.Service
 
-	bootOnce          sync.Once
-	releaseController *controller.Release
-	releaseCollector  *collector.Set
+	bootOnce         sync.Once
+	inventory        *inventory.Inventory
+	manager          *controller.Manager
+	releaseCollector *collector.Set
 }
 
 // New creates a new service with given configuration.
@@ -111,14 +122,152 @@ func New(config Config) (*Service, error) {
 		}
 	}
 
+	var releaseMutator *webhook.ReleaseMutator
+	{
+		c := webhook.ReleaseMutatorConfig{
+			Logger: config.Logger,
+		}
+
+		releaseMutator, err = webhook.NewReleaseMutator(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var releaseValidator *webhook.ReleaseValidator
+	{
+		c := webhook.ReleaseValidatorConfig{
+			Logger: config.Logger,
+
+			EnforceStatePromotion: config.Viper.GetBool(config.Flag.Service.Webhook.EnforceStatePromotion),
+			PromotionSoakTime:     config.Viper.GetDuration(config.Flag.Service.Webhook.PromotionSoakTime),
+		}
+
+		releaseValidator, err = webhook.NewReleaseValidator(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var metadataClient metadata.Interface
+	{
+		metadataClient, err = metadata.NewForConfig(restConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var inventorySources []inventory.Source
+	{
+		var sourceConfigs []inventory.LabelSourceConfig
+		if sources := config.Viper.GetString(config.Flag.Service.Inventory.Sources); sources != "" {
+			err = json.Unmarshal([]byte(sources), &sourceConfigs)
+			if err != nil {
+				return nil, microerror.Maskf(invalidConfigError, "%T.Flag.Service.Inventory.Sources must be a JSON list of sources: %s", config, err)
+			}
+		}
+
+		// Without configured sources the inventory uses its defaults.
+		inventorySources, err = inventory.NewSources(sourceConfigs)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var clusterInventory *inventory.Inventory
+	{
+		c := inventory.Config{
+			K8sClient:      k8sClient,
+			Logger:         config.Logger,
+			MetadataClient: metadataClient,
+			Sources:        inventorySources,
+		}
+
+		clusterInventory, err = inventory.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var eventRecorder record.EventRecorder
+	{
+		eventBroadcaster := record.NewBroadcaster()
+		eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{
+			Interface: k8sClient.K8sClient().CoreV1().Events(""),
+		})
+
+		eventRecorder = eventBroadcaster.NewRecorder(k8sClient.Scheme(), corev1.EventSource{
+			Component: project.Name(),
+		})
+	}
+
 	var releaseController *controller.Release
 	{
 		c := controller.ReleaseConfig{
+			EventRecorder: eventRecorder,
+			Inventory:     clusterInventory,
+			K8sClient:     k8sClient,
+			Logger:        config.Logger,
+
+			DeprecateEndOfLifeReleases: config.Viper.GetBool(config.Flag.Service.Controller.DeprecateEndOfLifeReleases),
+			SkipConfigs:                config.Viper.GetBool(config.Flag.Service.Controller.SkipConfigs),
+			StatePromotion: key.StatePromotion{
+				Enforce:  config.Viper.GetBool(config.Flag.Service.Webhook.EnforceStatePromotion),
+				SoakTime: config.Viper.GetDuration(config.Flag.Service.Webhook.PromotionSoakTime),
+			},
+			WebhookEnabled: config.Viper.GetString(config.Flag.Service.Webhook.Address) != "",
+		}
+
+		releaseController, err = controller.NewRelease(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var releaseSet *controller.ReleaseSet
+	{
+		c := controller.ReleaseSetConfig{
+			EventRecorder:  eventRecorder,
+			Inventory:      clusterInventory,
+			K8sClient:      k8sClient,
+			Logger:         config.Logger,
+			MetadataClient: metadataClient,
+
+			CheckUserConfigSecrets: config.Viper.GetBool(config.Flag.Service.Controller.CheckUserConfigSecrets),
+
+			ComponentDefaults: key.ComponentDefaults{
+				AppOperatorVersion: config.Viper.GetString(config.Flag.Service.Controller.AppDefaults.AppOperatorVersion),
+				InstallTimeout:     config.Viper.GetDuration(config.Flag.Service.Controller.AppDefaults.InstallTimeout),
+				Namespace:          config.Viper.GetString(config.Flag.Service.Controller.AppDefaults.Namespace),
+				UpgradeTimeout:     config.Viper.GetDuration(config.Flag.Service.Controller.AppDefaults.UpgradeTimeout),
+			},
+			ConfigWaitThreshold: config.Viper.GetDuration(config.Flag.Service.Controller.ConfigWaitThreshold),
+			DryRun:              config.Viper.GetBool(config.Flag.Service.Controller.DryRun),
+			MaxAppDeletions:     config.Viper.GetInt(config.Flag.Service.Controller.MaxAppDeletions),
+			MaxReleaseDeletions: config.Viper.GetInt(config.Flag.Service.Controller.MaxReleaseDeletions),
+			ReleaseRetention:    config.Viper.GetDuration(config.Flag.Service.Controller.ReleaseRetention),
+			SkipConfigs:         config.Viper.GetBool(config.Flag.Service.Controller.SkipConfigs),
+		}
+
+		releaseSet, err = controller.NewReleaseSet(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var controllerManager *controller.Manager
+	{
+		c := controller.ManagerConfig{
 			K8sClient: k8sClient,
 			Logger:    config.Logger,
+
+			Controllers: []controller.ManagedController{
+				releaseController,
+				releaseSet,
+			},
 		}
 
-		releaseController, err = controller.NewRelease(c)
+		controllerManager, err = controller.NewManager(c)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
@@ -129,6 +278,8 @@ func New(config Config) (*Service, error) {
 		c := collector.SetConfig{
 			K8sClient: k8sClient,
 			Logger:    config.Logger,
+
+			SkipConfigs: config.Viper.GetBool(config.Flag.Service.Controller.SkipConfigs),
 		}
 
 		releaseCollector, err = collector.NewSet(c)
@@ -138,11 +289,14 @@ func New(config Config) (*Service, error) {
 	}
 
 	s := &Service{
-		Version: versionService,
+		ReleaseMutator:   releaseMutator,
+		ReleaseValidator: releaseValidator,
+		Version:          versionService,
 
-		bootOnce:          sync.Once{},
-		releaseController: releaseController,
-		releaseCollector:  releaseCollector,
+		bootOnce:         sync.Once{},
+		inventory:        clusterInventory,
+		manager:          controllerManager,
+		releaseCollector: releaseCollector,
 	}
 
 	return s, nil
@@ -151,7 +305,19 @@ func New(config Config) (*Service, error) {
 // Boot starts top level service implementation.
 func (s *Service) Boot() {
 	s.bootOnce.Do(func() {
-		go s.releaseController.Boot(context.Background())
+		go func() {
+			// The controllers rely on the inventory being booted. Sources not
+			// synced in time are reported as failures by its snapshots.
+			err := s.inventory.Boot(context.Background())
+			if err != nil {
+				panic(microerror.JSON(err))
+			}
+
+			err = s.manager.Boot(context.Background())
+			if err != nil {
+				panic(microerror.JSON(err))
+			}
+		}()
 		go func() {
 			err := s.releaseCollector.Boot(context.Background())
 			if err != nil {
@@ -0,0 +1,90 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	admissionv1 "k8s.io/api/admission/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
+)
+
+type ReleaseMutatorConfig struct {
+	Logger micrologger.Logger
+}
+
+// ReleaseMutator implements admission.Handler and annotates Release CRs with
+// the user changing their state, so that release-operator can record who
+// made the transition in the status.
+type ReleaseMutator struct {
+	logger micrologger.Logger
+}
+
+func NewReleaseMutator(config ReleaseMutatorConfig) (*ReleaseMutator, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	m := &ReleaseMutator{
+		logger: config.Logger,
+	}
+
+	return m, nil
+}
+
+func (m *ReleaseMutator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	var release releasev1alpha1.Release
+	err := json.Unmarshal(req.Object.Raw, &release)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if req.Operation == admissionv1.Update {
+		var oldRelease releasev1alpha1.Release
+		err := json.Unmarshal(req.OldObject.Raw, &oldRelease)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+
+		if release.Spec.State == oldRelease.Spec.State {
+			return admission.Allowed("")
+		}
+	}
+
+	// The object is patched as unstructured JSON so that the patch only
+	// touches the annotations and not fields defaulted by the typed object.
+	var object map[string]interface{}
+	err = json.Unmarshal(req.Object.Raw, &object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	metadata, _ := object["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+		object["metadata"] = metadata
+	}
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	if annotations == nil {
+		annotations = map[string]interface{}{}
+		metadata["annotations"] = annotations
+	}
+	annotations[key.StateChangedByAnnotation] = req.UserInfo.Username
+	annotations[key.StateChangedToAnnotation] = string(release.Spec.State)
+
+	mutated, err := json.Marshal(object)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, mutated)
+}
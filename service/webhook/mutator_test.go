@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/google/go-cmp/cmp"
+	"gomodules.xyz/jsonpatch/v2"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
+)
+
+func Test_ReleaseMutator_Handle(t *testing.T) {
+	testCases := []struct {
+		name            string
+		operation       admissionv1.Operation
+		oldRelease      *releasev1alpha1.Release
+		release         *releasev1alpha1.Release
+		expectedPatches []jsonpatch.JsonPatchOperation
+	}{
+		{
+			name:      "case 0: created release is annotated with the user",
+			operation: admissionv1.Create,
+			release:   newTestRelease(),
+			expectedPatches: []jsonpatch.JsonPatchOperation{
+				{
+					Operation: "add",
+					Path:      "/metadata/annotations",
+					Value: map[string]interface{}{
+						key.StateChangedByAnnotation: "jane",
+						key.StateChangedToAnnotation: "active",
+					},
+				},
+			},
+		},
+		{
+			name:       "case 1: release changing its state is annotated with the user",
+			operation:  admissionv1.Update,
+			oldRelease: newTestRelease(),
+			release: func() *releasev1alpha1.Release {
+				r := newTestRelease()
+				r.Annotations = map[string]string{
+					key.StateChangedByAnnotation: "john",
+					key.StateChangedToAnnotation: "active",
+				}
+				r.Spec.State = releasev1alpha1.StateDeprecated
+				return r
+			}(),
+			expectedPatches: []jsonpatch.JsonPatchOperation{
+				{
+					Operation: "replace",
+					Path:      "/metadata/annotations/release-operator.giantswarm.io~1state-changed-by",
+					Value:     "jane",
+				},
+				{
+					Operation: "replace",
+					Path:      "/metadata/annotations/release-operator.giantswarm.io~1state-changed-to",
+					Value:     "deprecated",
+				},
+			},
+		},
+		{
+			name:            "case 2: release keeping its state is not patched",
+			operation:       admissionv1.Update,
+			oldRelease:      newTestRelease(),
+			release:         newTestRelease(),
+			expectedPatches: nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			m, err := NewReleaseMutator(ReleaseMutatorConfig{
+				Logger: microloggertest.New(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: tc.operation,
+					Object:    runtime.RawExtension{Raw: mustMarshal(t, tc.release)},
+					UserInfo: authenticationv1.UserInfo{
+						Username: "jane",
+					},
+				},
+			}
+			if tc.oldRelease != nil {
+				req.OldObject = runtime.RawExtension{Raw: mustMarshal(t, tc.oldRelease)}
+			}
+
+			resp := m.Handle(context.Background(), req)
+
+			if !resp.Allowed {
+				t.Fatalf("expected release to be allowed, got %#v", resp.Result)
+			}
+
+			patches := resp.Patches
+			sort.Slice(patches, func(i, j int) bool {
+				return patches[i].Path < patches[j].Path
+			})
+			if !cmp.Equal(patches, tc.expectedPatches) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedPatches, patches))
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	admissionv1 "k8s.io/api/admission/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
+)
+
+type ReleaseValidatorConfig struct {
+	Logger micrologger.Logger
+
+	// EnforceStatePromotion requires Releases to be created as wip or preview
+	// and to be ready for PromotionSoakTime before they may become active.
+	EnforceStatePromotion bool
+	PromotionSoakTime     time.Duration
+}
+
+// ReleaseValidator implements admission.Handler and rejects Release CRs
+// which would otherwise only fail later on during reconciliation.
+type ReleaseValidator struct {
+	logger micrologger.Logger
+
+	statePromotion key.StatePromotion
+}
+
+func NewReleaseValidator(config ReleaseValidatorConfig) (*ReleaseValidator, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	v := &ReleaseValidator{
+		logger: config.Logger,
+
+		statePromotion: key.StatePromotion{
+			Enforce:  config.EnforceStatePromotion,
+			SoakTime: config.PromotionSoakTime,
+		},
+	}
+
+	return v, nil
+}
+
+func (v *ReleaseValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation == admissionv1.Delete {
+		return admission.Allowed("")
+	}
+
+	var release releasev1alpha1.Release
+	err := json.Unmarshal(req.Object.Raw, &release)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	allErrs := validateRelease(&release)
+
+	if req.Operation == admissionv1.Create {
+		allErrs = append(allErrs, v.validateInitialState(&release)...)
+	}
+
+	if req.Operation == admissionv1.Update {
+		var oldRelease releasev1alpha1.Release
+		err := json.Unmarshal(req.OldObject.Raw, &oldRelease)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+
+		allErrs = append(allErrs, validateReleaseUpdate(&release, &oldRelease)...)
+		allErrs = append(allErrs, v.validateStateTransition(&release, &oldRelease, time.Now())...)
+	}
+
+	if len(allErrs) > 0 {
+		v.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("rejecting release %#q: %s", release.Name, allErrs.ToAggregate()))
+		return admission.Denied(allErrs.ToAggregate().Error())
+	}
+
+	return admission.Allowed("")
+}
+
+func validateRelease(release *releasev1alpha1.Release) field.ErrorList {
+	return validateReleaseSpec(release.Spec, field.NewPath("spec"))
+}
+
+func validateReleaseSpec(spec releasev1alpha1.ReleaseSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	componentNames := map[string]bool{}
+	for i, component := range spec.Components {
+		idxPath := fldPath.Child("components").Index(i)
+
+		if componentNames[component.Name] {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), component.Name))
+		}
+		componentNames[component.Name] = true
+
+		// The reference is passed through as the App version and must point to
+		// a build of the component version, e.g. 1.2.3-abc8675309 for 1.2.3.
+		if component.Reference != "" && !strings.HasPrefix(component.Reference, component.Version) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("reference"), component.Reference, fmt.Sprintf("must start with the component version %#q", component.Version)))
+		}
+	}
+
+	for i, component := range spec.Components {
+		idxPath := fldPath.Child("components").Index(i)
+
+		for j, dependency := range component.DependsOn {
+			if dependency == component.Name {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("dependsOn").Index(j), dependency, "component must not depend on itself"))
+			} else if !componentNames[dependency] {
+				allErrs = append(allErrs, field.NotFound(idxPath.Child("dependsOn").Index(j), dependency))
+			}
+		}
+	}
+
+	// Components and release apps deployed by release-operator share the
+	// App namespace, so their App names must not collide.
+	componentAppNames := map[string]bool{}
+	for _, component := range key.FilterComponents(spec.Components) {
+		componentAppNames[key.BuildAppName(component)] = true
+	}
+
+	appNames := map[string]bool{}
+	for i, app := range spec.Apps {
+		idxPath := fldPath.Child("apps").Index(i)
+
+		if appNames[app.Name] {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), app.Name))
+		}
+		appNames[app.Name] = true
+
+		if app.ReleaseOperatorDeploy && componentAppNames[key.BuildReleaseAppName(app)] {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("name"), app.Name, fmt.Sprintf("must not be deployed as App %#q of a component", key.BuildReleaseAppName(app))))
+		}
+	}
+
+	for i, app := range spec.Apps {
+		idxPath := fldPath.Child("apps").Index(i)
+
+		for j, dependency := range app.DependsOn {
+			if dependency == app.Name {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("dependsOn").Index(j), dependency, "app must not depend on itself"))
+			} else if !appNames[dependency] {
+				allErrs = append(allErrs, field.NotFound(idxPath.Child("dependsOn").Index(j), dependency))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// validateReleaseUpdate forbids changing what gets deployed for clusters
+// already running an active release. Only the state, end of life date, notice
+// and metadata may change unless the override annotation is set to "true".
+// Every other field of the spec, including fields added later on, is
+// protected.
+func validateReleaseUpdate(release, oldRelease *releasev1alpha1.Release) field.ErrorList {
+	if oldRelease.Spec.State != releasev1alpha1.StateActive || !oldRelease.Status.InUse {
+		return nil
+	}
+	if release.Annotations[key.AllowSpecChangesAnnotation] == "true" {
+		return nil
+	}
+
+	spec, oldSpec := protectedSpec(release.Spec), protectedSpec(oldRelease.Spec)
+	if apiequality.Semantic.DeepEqual(spec, oldSpec) {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+
+	fldPath := field.NewPath("spec")
+	detail := fmt.Sprintf("must not be changed while the release is active and in use, set annotation %#q to \"true\" to override", key.AllowSpecChangesAnnotation)
+
+	if !apiequality.Semantic.DeepEqual(spec.Components, oldSpec.Components) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("components"), detail))
+	}
+	if !apiequality.Semantic.DeepEqual(spec.Apps, oldSpec.Apps) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("apps"), detail))
+	}
+	if !apiequality.Semantic.DeepEqual(spec.Date, oldSpec.Date) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("date"), detail))
+	}
+	if len(allErrs) == 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath, fmt.Sprintf("only state, endOfLifeDate and notice %s", detail)))
+	}
+
+	return allErrs
+}
+
+// protectedSpec returns the given spec with the fields which may change while
+// a release is active and in use zeroed out.
+func protectedSpec(spec releasev1alpha1.ReleaseSpec) releasev1alpha1.ReleaseSpec {
+	spec.State = ""
+	spec.EndOfLifeDate = nil
+	spec.Notice = ""
+
+	return spec
+}
+
+// validateInitialState forbids creating Releases which are active right
+// away when state promotion is enforced, so that every release goes through
+// wip or preview first.
+func (v *ReleaseValidator) validateInitialState(release *releasev1alpha1.Release) field.ErrorList {
+	detail := key.StateTransitionError(*release, "", release.Spec.State, v.statePromotion, time.Now())
+	if detail == "" {
+		return nil
+	}
+
+	return field.ErrorList{
+		field.Forbidden(field.NewPath("spec", "state"), detail),
+	}
+}
+
+// validateStateTransition forbids moving deprecated releases back to wip.
+// When state promotion is enforced, releases may only become active once all
+// their components have been deployed for the promotion soak time.
+func (v *ReleaseValidator) validateStateTransition(release, oldRelease *releasev1alpha1.Release, now time.Time) field.ErrorList {
+	detail := key.StateTransitionError(*oldRelease, oldRelease.Spec.State, release.Spec.State, v.statePromotion, now)
+	if detail == "" {
+		return nil
+	}
+
+	return field.ErrorList{
+		field.Forbidden(field.NewPath("spec", "state"), detail),
+	}
+}
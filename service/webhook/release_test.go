@@ -0,0 +1,479 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/google/go-cmp/cmp"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
+	"github.com/giantswarm/release-operator/v4/service/controller/key"
+)
+
+func Test_ReleaseValidator_Handle(t *testing.T) {
+	testCases := []struct {
+		name            string
+		operation       admissionv1.Operation
+		release         *releasev1alpha1.Release
+		expectedAllowed bool
+		expectedMessage string
+	}{
+		{
+			name:            "case 0: valid release is allowed",
+			operation:       admissionv1.Create,
+			release:         newTestRelease(),
+			expectedAllowed: true,
+		},
+		{
+			name:      "case 1: duplicate component names are rejected",
+			operation: admissionv1.Create,
+			release: func() *releasev1alpha1.Release {
+				r := newTestRelease()
+				r.Spec.Components = append(r.Spec.Components, releasev1alpha1.ReleaseSpecComponent{
+					Name:    "app-operator",
+					Version: "2.0.0",
+				})
+				return r
+			}(),
+			expectedAllowed: false,
+			expectedMessage: `spec.components[2].name: Duplicate value: "app-operator"`,
+		},
+		{
+			name:      "case 2: dependency on an unknown app is rejected",
+			operation: admissionv1.Create,
+			release: func() *releasev1alpha1.Release {
+				r := newTestRelease()
+				r.Spec.Apps[1].DependsOn = []string{"unknown-app"}
+				return r
+			}(),
+			expectedAllowed: false,
+			expectedMessage: `spec.apps[1].dependsOn[0]: Not found: "unknown-app"`,
+		},
+		{
+			name:      "case 3: reference not starting with the version is rejected",
+			operation: admissionv1.Create,
+			release: func() *releasev1alpha1.Release {
+				r := newTestRelease()
+				r.Spec.Components[1].Reference = "1.0.1-a7663534964e4051d3ed957981c4f7885d60d15f"
+				return r
+			}(),
+			expectedAllowed: false,
+			expectedMessage: "spec.components[1].reference: Invalid value: \"1.0.1-a7663534964e4051d3ed957981c4f7885d60d15f\": must start with the component version `1.0.0`",
+		},
+		{
+			name:      "case 4: app depending on itself is rejected",
+			operation: admissionv1.Create,
+			release: func() *releasev1alpha1.Release {
+				r := newTestRelease()
+				r.Spec.Apps[0].DependsOn = []string{"cert-exporter"}
+				return r
+			}(),
+			expectedAllowed: false,
+			expectedMessage: `spec.apps[0].dependsOn[0]: Invalid value: "cert-exporter": app must not depend on itself`,
+		},
+		{
+			name:            "case 5: deletion is always allowed",
+			operation:       admissionv1.Delete,
+			release:         nil,
+			expectedAllowed: true,
+		},
+		{
+			name:      "case 6: dependency on an unknown component is rejected",
+			operation: admissionv1.Create,
+			release: func() *releasev1alpha1.Release {
+				r := newTestRelease()
+				r.Spec.Components[1].DependsOn = []string{"unknown-component"}
+				return r
+			}(),
+			expectedAllowed: false,
+			expectedMessage: `spec.components[1].dependsOn[0]: Not found: "unknown-component"`,
+		},
+		{
+			name:      "case 7: component depending on itself is rejected",
+			operation: admissionv1.Create,
+			release: func() *releasev1alpha1.Release {
+				r := newTestRelease()
+				r.Spec.Components[1].DependsOn = []string{"deploy-me"}
+				return r
+			}(),
+			expectedAllowed: false,
+			expectedMessage: `spec.components[1].dependsOn[0]: Invalid value: "deploy-me": component must not depend on itself`,
+		},
+		{
+			name:      "case 8: release app with the App name of a component is rejected",
+			operation: admissionv1.Create,
+			release: func() *releasev1alpha1.Release {
+				r := newTestRelease()
+				r.Spec.Apps = append(r.Spec.Apps, releasev1alpha1.ReleaseSpecApp{
+					Name:                  "deploy-me",
+					ReleaseOperatorDeploy: true,
+					Version:               "1.0.0",
+				})
+				return r
+			}(),
+			expectedAllowed: false,
+			expectedMessage: "spec.apps[2].name: Invalid value: \"deploy-me\": must not be deployed as App `deploy-me-1.0.0` of a component",
+		},
+		{
+			name:      "case 9: release app with the App name of a component not deployed by release-operator is allowed",
+			operation: admissionv1.Create,
+			release: func() *releasev1alpha1.Release {
+				r := newTestRelease()
+				r.Spec.Apps = append(r.Spec.Apps, releasev1alpha1.ReleaseSpecApp{
+					Name:                  "app-operator",
+					ReleaseOperatorDeploy: true,
+					Version:               "1.0.0",
+				})
+				return r
+			}(),
+			expectedAllowed: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			v, err := NewReleaseValidator(ReleaseValidatorConfig{
+				Logger: microloggertest.New(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: tc.operation,
+				},
+			}
+			if tc.release != nil {
+				req.Object = runtime.RawExtension{Raw: mustMarshal(t, tc.release)}
+			}
+
+			resp := v.Handle(context.Background(), req)
+
+			if !cmp.Equal(resp.Allowed, tc.expectedAllowed) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedAllowed, resp.Allowed))
+			}
+			if tc.expectedMessage != "" && !cmp.Equal(resp.Result.Message, tc.expectedMessage) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedMessage, resp.Result.Message))
+			}
+		})
+	}
+}
+
+func Test_ReleaseValidator_HandleUpdate(t *testing.T) {
+	inUse := func(r *releasev1alpha1.Release) *releasev1alpha1.Release {
+		r.Status.InUse = true
+		return r
+	}
+
+	testCases := []struct {
+		name            string
+		oldRelease      *releasev1alpha1.Release
+		release         *releasev1alpha1.Release
+		expectedAllowed bool
+		expectedMessage string
+	}{
+		{
+			name:       "case 0: changing components of an active release in use is rejected",
+			oldRelease: inUse(newTestRelease()),
+			release: func() *releasev1alpha1.Release {
+				r := inUse(newTestRelease())
+				r.Spec.Components[1].Version = "1.1.0"
+				r.Spec.Components[1].Reference = ""
+				return r
+			}(),
+			expectedAllowed: false,
+			expectedMessage: "spec.components: Forbidden: must not be changed while the release is active and in use, set annotation `release-operator.giantswarm.io/allow-spec-changes` to \"true\" to override",
+		},
+		{
+			name:       "case 1: changing apps of an active release in use is rejected",
+			oldRelease: inUse(newTestRelease()),
+			release: func() *releasev1alpha1.Release {
+				r := inUse(newTestRelease())
+				r.Spec.Apps[0].Version = "1.3.0"
+				return r
+			}(),
+			expectedAllowed: false,
+			expectedMessage: "spec.apps: Forbidden: must not be changed while the release is active and in use, set annotation `release-operator.giantswarm.io/allow-spec-changes` to \"true\" to override",
+		},
+		{
+			name:       "case 2: changing state, end of life date, notice and annotations is allowed",
+			oldRelease: inUse(newTestRelease()),
+			release: func() *releasev1alpha1.Release {
+				r := inUse(newTestRelease())
+				r.Annotations = map[string]string{"giantswarm.io/release-notes": "https://example.com"}
+				r.Spec.EndOfLifeDate = &metav1.Time{Time: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+				r.Spec.Notice = "Please upgrade."
+				r.Spec.State = releasev1alpha1.StateDeprecated
+				return r
+			}(),
+			expectedAllowed: true,
+		},
+		{
+			name:       "case 3: changing components of an active release in use is allowed with the override annotation",
+			oldRelease: inUse(newTestRelease()),
+			release: func() *releasev1alpha1.Release {
+				r := inUse(newTestRelease())
+				r.Annotations = map[string]string{key.AllowSpecChangesAnnotation: "true"}
+				r.Spec.Components[1].Version = "1.1.0"
+				r.Spec.Components[1].Reference = ""
+				return r
+			}(),
+			expectedAllowed: true,
+		},
+		{
+			name:       "case 4: changing components of an active release in use is rejected with an override annotation not set to true",
+			oldRelease: inUse(newTestRelease()),
+			release: func() *releasev1alpha1.Release {
+				r := inUse(newTestRelease())
+				r.Annotations = map[string]string{key.AllowSpecChangesAnnotation: "false"}
+				r.Spec.Components[1].Version = "1.1.0"
+				r.Spec.Components[1].Reference = ""
+				return r
+			}(),
+			expectedAllowed: false,
+			expectedMessage: "spec.components: Forbidden: must not be changed while the release is active and in use, set annotation `release-operator.giantswarm.io/allow-spec-changes` to \"true\" to override",
+		},
+		{
+			name:       "case 5: changing the date of an active release in use is rejected",
+			oldRelease: inUse(newTestRelease()),
+			release: func() *releasev1alpha1.Release {
+				r := inUse(newTestRelease())
+				r.Spec.Date = &metav1.Time{Time: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+				return r
+			}(),
+			expectedAllowed: false,
+			expectedMessage: "spec.date: Forbidden: must not be changed while the release is active and in use, set annotation `release-operator.giantswarm.io/allow-spec-changes` to \"true\" to override",
+		},
+		{
+			name:       "case 6: changing components of an active release not in use is allowed",
+			oldRelease: newTestRelease(),
+			release: func() *releasev1alpha1.Release {
+				r := newTestRelease()
+				r.Spec.Components[1].Version = "1.1.0"
+				r.Spec.Components[1].Reference = ""
+				return r
+			}(),
+			expectedAllowed: true,
+		},
+		{
+			name: "case 7: changing components of a wip release in use is allowed",
+			oldRelease: func() *releasev1alpha1.Release {
+				r := inUse(newTestRelease())
+				r.Spec.State = releasev1alpha1.StateWIP
+				return r
+			}(),
+			release: func() *releasev1alpha1.Release {
+				r := inUse(newTestRelease())
+				r.Spec.State = releasev1alpha1.StateWIP
+				r.Spec.Components[1].Version = "1.1.0"
+				r.Spec.Components[1].Reference = ""
+				return r
+			}(),
+			expectedAllowed: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			v, err := NewReleaseValidator(ReleaseValidatorConfig{
+				Logger: microloggertest.New(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Update,
+					Object:    runtime.RawExtension{Raw: mustMarshal(t, tc.release)},
+					OldObject: runtime.RawExtension{Raw: mustMarshal(t, tc.oldRelease)},
+				},
+			}
+
+			resp := v.Handle(context.Background(), req)
+
+			if !cmp.Equal(resp.Allowed, tc.expectedAllowed) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedAllowed, resp.Allowed))
+			}
+			if tc.expectedMessage != "" && !cmp.Equal(resp.Result.Message, tc.expectedMessage) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedMessage, resp.Result.Message))
+			}
+		})
+	}
+}
+
+func Test_ReleaseValidator_HandleStateTransition(t *testing.T) {
+	withState := func(r *releasev1alpha1.Release, state releasev1alpha1.ReleaseState) *releasev1alpha1.Release {
+		r.Spec.State = state
+		return r
+	}
+	readySince := func(r *releasev1alpha1.Release, since time.Time) *releasev1alpha1.Release {
+		r.Status.Ready = true
+		r.Status.Conditions = []metav1.Condition{
+			{
+				Type:               releasev1alpha1.ConditionComponentsDeployed,
+				Status:             metav1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(since),
+			},
+		}
+		return r
+	}
+
+	testCases := []struct {
+		name            string
+		enforce         bool
+		operation       admissionv1.Operation
+		oldRelease      *releasev1alpha1.Release
+		release         *releasev1alpha1.Release
+		expectedAllowed bool
+		expectedMessage string
+	}{
+		{
+			name:            "case 0: moving a deprecated release back to wip is rejected",
+			operation:       admissionv1.Update,
+			oldRelease:      withState(newTestRelease(), releasev1alpha1.StateDeprecated),
+			release:         withState(newTestRelease(), releasev1alpha1.StateWIP),
+			expectedAllowed: false,
+			expectedMessage: "spec.state: Forbidden: must not be changed from `deprecated` to `wip`",
+		},
+		{
+			name:            "case 1: promoting a release which is not ready is allowed without enforcement",
+			operation:       admissionv1.Update,
+			oldRelease:      withState(newTestRelease(), releasev1alpha1.StatePreview),
+			release:         newTestRelease(),
+			expectedAllowed: true,
+		},
+		{
+			name:            "case 2: creating an active release is rejected with enforcement",
+			enforce:         true,
+			operation:       admissionv1.Create,
+			release:         newTestRelease(),
+			expectedAllowed: false,
+			expectedMessage: "spec.state: Forbidden: must be `wip` or `preview` on creation, releases become active once they are ready",
+		},
+		{
+			name:            "case 3: creating a wip release is allowed with enforcement",
+			enforce:         true,
+			operation:       admissionv1.Create,
+			release:         withState(newTestRelease(), releasev1alpha1.StateWIP),
+			expectedAllowed: true,
+		},
+		{
+			name:            "case 4: promoting a release which is not ready is rejected with enforcement",
+			enforce:         true,
+			operation:       admissionv1.Update,
+			oldRelease:      withState(newTestRelease(), releasev1alpha1.StatePreview),
+			release:         newTestRelease(),
+			expectedAllowed: false,
+			expectedMessage: "spec.state: Forbidden: must not be active before all components are deployed",
+		},
+		{
+			name:            "case 5: promoting a release ready for less than the soak time is rejected with enforcement",
+			enforce:         true,
+			operation:       admissionv1.Update,
+			oldRelease:      readySince(withState(newTestRelease(), releasev1alpha1.StatePreview), time.Now().Add(-time.Minute)),
+			release:         newTestRelease(),
+			expectedAllowed: false,
+		},
+		{
+			name:            "case 6: promoting a release ready for longer than the soak time is allowed with enforcement",
+			enforce:         true,
+			operation:       admissionv1.Update,
+			oldRelease:      readySince(withState(newTestRelease(), releasev1alpha1.StatePreview), time.Now().Add(-2*time.Hour)),
+			release:         newTestRelease(),
+			expectedAllowed: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			v, err := NewReleaseValidator(ReleaseValidatorConfig{
+				Logger: microloggertest.New(),
+
+				EnforceStatePromotion: tc.enforce,
+				PromotionSoakTime:     time.Hour,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: tc.operation,
+					Object:    runtime.RawExtension{Raw: mustMarshal(t, tc.release)},
+				},
+			}
+			if tc.oldRelease != nil {
+				req.OldObject = runtime.RawExtension{Raw: mustMarshal(t, tc.oldRelease)}
+			}
+
+			resp := v.Handle(context.Background(), req)
+
+			if !cmp.Equal(resp.Allowed, tc.expectedAllowed) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedAllowed, resp.Allowed))
+			}
+			if tc.expectedMessage != "" && !cmp.Equal(resp.Result.Message, tc.expectedMessage) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedMessage, resp.Result.Message))
+			}
+		})
+	}
+}
+
+func newTestRelease() *releasev1alpha1.Release {
+	return &releasev1alpha1.Release{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "v11.3.0",
+		},
+		Spec: releasev1alpha1.ReleaseSpec{
+			Apps: []releasev1alpha1.ReleaseSpecApp{
+				{
+					Name:    "cert-exporter",
+					Version: "1.2.1",
+				},
+				{
+					Name:      "net-exporter",
+					Version:   "1.6.0",
+					DependsOn: []string{"cert-exporter"},
+				},
+			},
+			Components: []releasev1alpha1.ReleaseSpecComponent{
+				{
+					Name:    "app-operator",
+					Version: "1.0.0",
+				},
+				{
+					Catalog:               "control-plane-catalog",
+					Name:                  "deploy-me",
+					Reference:             "1.0.0-a7663534964e4051d3ed957981c4f7885d60d15f",
+					ReleaseOperatorDeploy: true,
+					Version:               "1.0.0",
+				},
+			},
+			State: releasev1alpha1.StateActive,
+		},
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}